@@ -0,0 +1,72 @@
+// Package auth provides authentication providers for HTTP requests
+// 包 auth 提供 HTTP 请求的认证提供者
+package auth
+
+import (
+	"net/http"
+)
+
+// Provider adds credentials to outgoing requests
+// Provider 为发出的请求添加认证信息
+type Provider interface {
+	// Authorize adds credentials to req before it is sent
+	// Authorize 在发送 req 之前为其添加认证信息
+	Authorize(req *http.Request) error
+
+	// Challenge is called when resp answered req with 401 Unauthorized. It returns true when the provider
+	// updated its state (for example a new digest nonce or a refreshed token) and req should be sent once more.
+	// Challenge 在 resp 以 401 Unauthorized 响应 req 时调用。当提供者更新了自身状态
+	// （例如新的摘要 nonce 或刷新后的令牌）且应当再发送一次 req 时返回 true。
+	Challenge(req *http.Request, resp *http.Response) bool
+}
+
+// basicAuth sends HTTP Basic credentials
+// basicAuth 发送 HTTP Basic 认证信息
+type basicAuth struct {
+	username string // User name / 用户名
+	password string // Password / 密码
+}
+
+// Basic creates a provider sending HTTP Basic credentials
+// Basic 创建一个发送 HTTP Basic 认证信息的提供者
+func Basic(username, password string) Provider {
+	return &basicAuth{username: username, password: password}
+}
+
+// Authorize sets the Basic Authorization header
+// Authorize 设置 Basic Authorization 头
+func (b *basicAuth) Authorize(req *http.Request) error {
+	req.SetBasicAuth(b.username, b.password)
+	return nil
+}
+
+// Challenge never resends, the credentials do not change
+// Challenge 从不重新发送，认证信息不会改变
+func (b *basicAuth) Challenge(*http.Request, *http.Response) bool {
+	return false
+}
+
+// bearerAuth sends a static bearer token
+// bearerAuth 发送静态的 Bearer 令牌
+type bearerAuth struct {
+	token string // Bearer token / Bearer 令牌
+}
+
+// Bearer creates a provider sending a static bearer token
+// Bearer 创建一个发送静态 Bearer 令牌的提供者
+func Bearer(token string) Provider {
+	return &bearerAuth{token: token}
+}
+
+// Authorize sets the Bearer Authorization header
+// Authorize 设置 Bearer Authorization 头
+func (b *bearerAuth) Authorize(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return nil
+}
+
+// Challenge never resends, the token does not change
+// Challenge 从不重新发送，令牌不会改变
+func (b *bearerAuth) Challenge(*http.Request, *http.Response) bool {
+	return false
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// digestChallenge is a parsed WWW-Authenticate Digest challenge
+// digestChallenge 是解析后的 WWW-Authenticate Digest 质询
+type digestChallenge struct {
+	realm     string // Protection space / 保护域
+	nonce     string // Server nonce / 服务器随机数
+	opaque    string // Opaque value echoed back / 原样返回的 opaque 值
+	algorithm string // Hash algorithm / 哈希算法
+	qop       string // Selected quality of protection, empty for RFC 2069 / 选定的保护质量，RFC 2069 时为空
+}
+
+// digestAuth answers HTTP Digest challenges (RFC 7616)
+// digestAuth 响应 HTTP Digest 质询 (RFC 7616)
+type digestAuth struct {
+	username  string           // User name / 用户名
+	password  string           // Password / 密码
+	challenge *digestChallenge // Last challenge, nil before the first 401 / 最近的质询，首次 401 之前为 nil
+	nc        int              // Nonce count for the current nonce / 当前 nonce 的使用次数
+	mu        sync.Mutex       // Mutex for challenge and nc / 质询和计数的互斥锁
+}
+
+// Digest creates a provider answering HTTP Digest challenges. The first request is sent without credentials,
+// the 401 challenge is answered by resending it, and later requests reuse the nonce.
+// Digest 创建一个响应 HTTP Digest 质询的提供者。第一个请求不带认证信息发送，
+// 通过重新发送来响应 401 质询，之后的请求复用该 nonce。
+func Digest(username, password string) Provider {
+	return &digestAuth{username: username, password: password}
+}
+
+// Authorize sets the Digest Authorization header once a challenge is known
+// Authorize 在已知质询后设置 Digest Authorization 头
+func (d *digestAuth) Authorize(req *http.Request) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.challenge == nil {
+		return nil
+	}
+	d.nc++
+	header, err := d.authorization(req.Method, req.URL.RequestURI(), d.nc)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", header)
+	return nil
+}
+
+// Challenge stores a new Digest challenge and resends, unless the current nonce was rejected without being stale
+// Challenge 保存新的 Digest 质询并重新发送，当前 nonce 在未过期的情况下被拒绝时除外
+func (d *digestAuth) Challenge(req *http.Request, resp *http.Response) bool {
+	for _, value := range resp.Header.Values("WWW-Authenticate") {
+		scheme, params, _ := strings.Cut(value, " ")
+		if !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+		fields := parseAuthParams(params)
+		challenge := &digestChallenge{
+			realm:     fields["realm"],
+			nonce:     fields["nonce"],
+			opaque:    fields["opaque"],
+			algorithm: fields["algorithm"],
+		}
+		for _, qop := range strings.Split(fields["qop"], ",") {
+			if strings.TrimSpace(qop) == "auth" {
+				challenge.qop = "auth"
+			}
+		}
+		if challenge.nonce == "" || hashFunc(challenge.algorithm) == nil {
+			return false
+		}
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		// Wrong credentials are rejected with a fresh challenge for a request that already answered one
+		// 已经响应过质询的请求再次收到质询，说明认证信息错误
+		answered := req.Header.Get("Authorization") != ""
+		if answered && !strings.EqualFold(fields["stale"], "true") {
+			return false
+		}
+		d.challenge, d.nc = challenge, 0
+		return true
+	}
+	return false
+}
+
+// authorization computes the Digest Authorization header value
+// authorization 计算 Digest Authorization 头的值
+func (d *digestAuth) authorization(method, uri string, nc int) (string, error) {
+	c := d.challenge
+	newHash := hashFunc(c.algorithm)
+	digest := func(s string) string {
+		h := newHash()
+		h.Write([]byte(s))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	cnonceBytes := make([]byte, 16)
+	if _, err := rand.Read(cnonceBytes); err != nil {
+		return "", fmt.Errorf("generate digest cnonce error: %w", err)
+	}
+	cnonce := hex.EncodeToString(cnonceBytes)
+	ncValue := fmt.Sprintf("%08x", nc)
+
+	ha1 := digest(d.username + ":" + c.realm + ":" + d.password)
+	if strings.HasSuffix(strings.ToLower(c.algorithm), "-sess") {
+		ha1 = digest(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	ha2 := digest(method + ":" + uri)
+
+	var response string
+	if c.qop == "" {
+		response = digest(ha1 + ":" + c.nonce + ":" + ha2)
+	} else {
+		response = digest(ha1 + ":" + c.nonce + ":" + ncValue + ":" + cnonce + ":" + c.qop + ":" + ha2)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`,
+		d.username, c.realm, c.nonce, uri, response)
+	if c.algorithm != "" {
+		fmt.Fprintf(&b, ", algorithm=%s", c.algorithm)
+	}
+	if c.opaque != "" {
+		fmt.Fprintf(&b, `, opaque="%s"`, c.opaque)
+	}
+	if c.qop != "" {
+		fmt.Fprintf(&b, `, qop=%s, nc=%s, cnonce="%s"`, c.qop, ncValue, cnonce)
+	}
+	return b.String(), nil
+}
+
+// hashFunc returns the hash of a Digest algorithm, nil if unsupported
+// hashFunc 返回 Digest 算法对应的哈希函数，不支持时返回 nil
+func hashFunc(algorithm string) func() hash.Hash {
+	switch strings.ToUpper(strings.TrimSuffix(strings.ToLower(algorithm), "-sess")) {
+	case "", "MD5":
+		return md5.New
+	case "SHA-256":
+		return sha256.New
+	}
+	return nil
+}
+
+// parseAuthParams parses comma separated key=value or key="value" authentication parameters
+// parseAuthParams 解析以逗号分隔的 key=value 或 key="value" 认证参数
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimSpace(rest)
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			// Quoted string, backslash escapes the next character
+			// 带引号的字符串，反斜杠转义下一个字符
+			var b strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				b.WriteByte(rest[i])
+			}
+			value, rest = b.String(), rest[min(i+1, len(rest)):]
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+			rest = "," + rest
+		}
+		params[key] = value
+		_, s, _ = strings.Cut(rest, ",")
+		if !strings.Contains(rest, ",") {
+			s = ""
+		}
+	}
+	return params
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultExpiryDelta is how long before expiry a token is refreshed when OAuth2Config.ExpiryDelta is zero
+// defaultExpiryDelta 是 OAuth2Config.ExpiryDelta 为零时，令牌在过期前多久刷新
+const defaultExpiryDelta = 10 * time.Second
+
+// OAuth2Config configures the token endpoint of an OAuth2 provider
+// OAuth2Config 配置 OAuth2 提供者的令牌端点
+type OAuth2Config struct {
+	TokenURL     string            // Token endpoint URL / 令牌端点地址
+	ClientID     string            // Client identifier / 客户端标识
+	ClientSecret string            // Client secret / 客户端密钥
+	Scopes       []string          // Requested scopes / 请求的权限范围
+	Params       url.Values        // Extra token request parameters, e.g. audience / 额外的令牌请求参数，例如 audience
+	ExpiryDelta  time.Duration     // Refresh this long before expiry, 10s when zero / 在过期前多久刷新，为零时为 10 秒
+	Client       *http.Client      // Client used for token requests, the requester's transport when nil / 用于令牌请求的客户端，为 nil 时使用请求器的传输层
+	Header       map[string]string // Extra token request headers / 额外的令牌请求头
+}
+
+// transportContextKey is the context key for the transport token requests are sent with
+// transportContextKey 是发送令牌请求所用传输层的上下文键
+type transportContextKey struct{}
+
+// WithTransport returns a copy of ctx sending the token requests of providers without their own client with transport.
+// The requesters bind their transport this way, so token requests follow their proxy, TLS and dial settings.
+// WithTransport 返回一个 ctx 副本，使没有自己客户端的提供者通过 transport 发送令牌请求。
+// 请求器通过它绑定自身的传输层，因此令牌请求遵循请求器的代理、TLS 和拨号设置。
+func WithTransport(ctx context.Context, transport http.RoundTripper) context.Context {
+	return context.WithValue(ctx, transportContextKey{}, transport)
+}
+
+// Token is an OAuth2 access token
+// Token 是 OAuth2 访问令牌
+type Token struct {
+	AccessToken  string    // Access token / 访问令牌
+	TokenType    string    // Token type, usually "Bearer" / 令牌类型，通常为 "Bearer"
+	RefreshToken string    // Refresh token, if issued / 刷新令牌（如果签发）
+	Expiry       time.Time // Expiry time, zero if the token does not expire / 过期时间，令牌不过期时为零值
+}
+
+// tokenResponse is the JSON body of a token endpoint response
+// tokenResponse 是令牌端点响应的 JSON 内容
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Error        string `json:"error"`
+	Description  string `json:"error_description"`
+}
+
+// OAuth2 is a provider that obtains, caches and refreshes OAuth2 access tokens.
+// A 401 response invalidates the cached token and the request is resent once with a new one.
+// OAuth2 是获取、缓存并刷新 OAuth2 访问令牌的提供者。收到 401 响应时会作废缓存的令牌，并使用新令牌重新发送一次请求。
+type OAuth2 struct {
+	config       OAuth2Config // Token endpoint configuration / 令牌端点配置
+	grantType    string       // Grant type used when no refresh token is held / 未持有刷新令牌时使用的授权类型
+	refreshToken string       // Current refresh token / 当前的刷新令牌
+	token        *Token       // Cached token / 缓存的令牌
+	mu           sync.Mutex   // Serializes token requests / 串行化令牌请求
+}
+
+// ClientCredentials creates a provider using the OAuth2 client credentials grant
+// ClientCredentials 创建一个使用 OAuth2 客户端凭证授权的提供者
+func ClientCredentials(config OAuth2Config) *OAuth2 {
+	return &OAuth2{config: config, grantType: "client_credentials"}
+}
+
+// RefreshToken creates a provider that obtains access tokens with an OAuth2 refresh token,
+// rotated refresh tokens returned by the server are kept
+// RefreshToken 创建一个通过 OAuth2 刷新令牌获取访问令牌的提供者，服务器返回的新刷新令牌会被保存
+func RefreshToken(config OAuth2Config, refreshToken string) *OAuth2 {
+	return &OAuth2{config: config, grantType: "refresh_token", refreshToken: refreshToken}
+}
+
+// Authorize sets the Authorization header, fetching a new token when none is cached or it is about to expire
+// Authorize 设置 Authorization 头，没有缓存令牌或令牌即将过期时获取新令牌
+func (o *OAuth2) Authorize(req *http.Request) error {
+	token, err := o.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	tokenType := token.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	req.Header.Set("Authorization", tokenType+" "+token.AccessToken)
+	return nil
+}
+
+// Challenge drops the cached token if req was sent with it, so the resent request uses a new token
+// Challenge 如果 req 使用了缓存的令牌则将其作废，使重新发送的请求使用新令牌
+func (o *OAuth2) Challenge(req *http.Request, _ *http.Response) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Keep the token if another request already replaced the rejected one
+	// 如果其他请求已经替换了被拒绝的令牌，则保留当前令牌
+	if o.token != nil && strings.HasSuffix(req.Header.Get("Authorization"), " "+o.token.AccessToken) {
+		o.token = nil
+	}
+	return true
+}
+
+// Token returns the cached token, requesting a new one when needed
+// Token 返回缓存的令牌，必要时请求新令牌
+func (o *OAuth2) Token(ctx context.Context) (*Token, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	expiryDelta := o.config.ExpiryDelta
+	if expiryDelta <= 0 {
+		expiryDelta = defaultExpiryDelta
+	}
+	if o.token != nil && (o.token.Expiry.IsZero() || time.Until(o.token.Expiry) > expiryDelta) {
+		return o.token, nil
+	}
+
+	token, err := o.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o.token = token
+	if token.RefreshToken != "" {
+		o.refreshToken = token.RefreshToken
+	}
+	return token, nil
+}
+
+// fetch requests a new token from the token endpoint
+// fetch 从令牌端点请求新令牌
+func (o *OAuth2) fetch(ctx context.Context) (*Token, error) {
+	form := url.Values{}
+	for key, values := range o.config.Params {
+		form[key] = values
+	}
+	if o.refreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", o.refreshToken)
+	} else {
+		form.Set("grant_type", o.grantType)
+	}
+	if len(o.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.config.Scopes, " "))
+	}
+
+	// Without a client of its own the token request goes through the requester's transport. It follows the cancellation
+	// of the request but not its per-request settings, such as a Unix socket, which address the API rather than the token endpoint.
+	// 没有自己的客户端时，令牌请求通过请求器的传输层发送。它跟随请求的取消，但不使用其单请求设置（例如 Unix 套接字），
+	// 这些设置针对的是 API 而不是令牌端点。
+	client := o.config.Client
+	if client == nil {
+		client = http.DefaultClient
+		if transport, ok := ctx.Value(transportContextKey{}).(http.RoundTripper); ok {
+			client = &http.Client{Transport: transport}
+			tokenCtx, cancel := context.WithCancelCause(context.Background())
+			defer cancel(nil)
+			stop := context.AfterFunc(ctx, func() { cancel(context.Cause(ctx)) })
+			defer stop()
+			ctx = tokenCtx
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("new token request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	for key, value := range o.config.Header {
+		req.Header.Set(key, value)
+	}
+	if o.config.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(o.config.ClientID), url.QueryEscape(o.config.ClientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read token response error: %w", err)
+	}
+	var tr tokenResponse
+	if err = json.Unmarshal(body, &tr); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("decode token response error: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || tr.AccessToken == "" {
+		if tr.Error != "" {
+			return nil, fmt.Errorf("token request failed with status %d: %s %s", resp.StatusCode, tr.Error, tr.Description)
+		}
+		return nil, fmt.Errorf("token request failed with status %d", resp.StatusCode)
+	}
+
+	token := &Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+	}
+	if tr.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
@@ -0,0 +1,288 @@
+// Package balancer provides client-side load balancing across upstream endpoints
+// 包 balancer 提供在多个上游端点之间的客户端负载均衡
+package balancer
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// Defaults used when a Config field is zero
+// Config 字段为零时使用的默认值
+const (
+	DefaultMaxFails = 3                // Default consecutive failures ejecting an endpoint / 默认使端点被摘除的连续失败次数
+	DefaultCooldown = 30 * time.Second // Default ejection time / 默认摘除时长
+)
+
+// virtualNodes is the number of points per unit of weight an endpoint has on the consistent hash ring
+// virtualNodes 是每单位权重的端点在一致性哈希环上的节点数
+const virtualNodes = 100
+
+// Strategy chooses the endpoint of a request
+// Strategy 选择请求的端点
+type Strategy int
+
+const (
+	RoundRobin     Strategy = iota // Endpoints in turn / 依次轮询端点
+	LeastInFlight                  // Endpoint with the fewest requests in flight / 进行中请求最少的端点
+	Weighted                       // Smooth weighted round robin by Endpoint.Weight / 按 Endpoint.Weight 平滑加权轮询
+	ConsistentHash                 // Same Config.Key, same endpoint while it is healthy / 相同的 Config.Key 在端点健康时总是选择同一端点
+)
+
+// Endpoint is an upstream base URL
+// Endpoint 是一个上游基础地址
+type Endpoint struct {
+	URL    string // Absolute base URL relative request URLs are appended to / 相对请求地址拼接的绝对基础地址
+	Weight int    // Weight for Weighted and ConsistentHash, 1 when zero / Weighted 和 ConsistentHash 使用的权重，为零时为 1
+}
+
+// Endpoints creates endpoints of weight 1 from base URLs
+// Endpoints 使用基础地址创建权重为 1 的端点
+func Endpoints(urls ...string) []Endpoint {
+	endpoints := make([]Endpoint, len(urls))
+	for i, u := range urls {
+		endpoints[i] = Endpoint{URL: u, Weight: 1}
+	}
+	return endpoints
+}
+
+// Config describes the endpoints, how they are chosen and when failing ones are ejected
+// Config 描述端点、端点的选择方式以及何时摘除失败的端点
+type Config struct {
+	Endpoints []Endpoint                           // Upstream endpoints / 上游端点
+	Strategy  Strategy                             // Balancing strategy / 负载均衡策略
+	Key       func(req *request.Request) string    // Hash key for ConsistentHash, requests with an empty key use round robin / ConsistentHash 的哈希键，键为空的请求使用轮询
+	MaxFails  int                                  // Consecutive failures ejecting an endpoint, DefaultMaxFails when zero / 使端点被摘除的连续失败次数，为零时使用 DefaultMaxFails
+	Cooldown  time.Duration                        // Time an ejected endpoint is skipped, DefaultCooldown when zero / 被摘除端点被跳过的时间，为零时使用 DefaultCooldown
+	IsFailure func(statusCode int, err error) bool // Decides whether an attempt failed, DefaultFailure when nil / 判断尝试是否失败，为 nil 时使用 DefaultFailure
+}
+
+// DefaultFailure counts retryable errors (see reqerr.IsRetryable) and 5xx responses as failures
+// DefaultFailure 将可重试错误（见 reqerr.IsRetryable）和 5xx 响应计为失败
+func DefaultFailure(statusCode int, err error) bool {
+	if err != nil {
+		return reqerr.IsRetryable(err)
+	}
+	return statusCode >= 500
+}
+
+// Status is a snapshot of an endpoint
+// Status 是端点的快照
+type Status struct {
+	URL          string    // Base URL / 基础地址
+	Weight       int       // Weight / 权重
+	InFlight     int       // Requests in flight / 进行中的请求数
+	Failures     int       // Consecutive failures / 连续失败次数
+	Ejected      bool      // Whether the endpoint is currently skipped / 端点当前是否被跳过
+	EjectedUntil time.Time // End of the current ejection / 当前摘除的结束时间
+}
+
+// Balancer spreads the attempts of relative request URLs over endpoints, passively tracking their health:
+// endpoints failing MaxFails times in a row are skipped for Cooldown. When every endpoint is ejected, all are used.
+// Balancer 将相对请求地址的尝试分散到各个端点，并被动跟踪端点的健康状况：
+// 连续失败 MaxFails 次的端点在 Cooldown 时间内被跳过。所有端点都被摘除时使用全部端点。
+type Balancer struct {
+	config    Config      // Configuration with defaults applied / 应用默认值后的配置
+	endpoints []*endpoint // Endpoints in configuration order / 按配置顺序排列的端点
+	ring      []ringNode  // Consistent hash ring sorted by hash / 按哈希值排序的一致性哈希环
+	next      int         // Round robin position / 轮询位置
+	mu        sync.Mutex  // Mutex for endpoint state / 端点状态的互斥锁
+}
+
+// endpoint is the state of an upstream endpoint
+// endpoint 是上游端点的状态
+type endpoint struct {
+	url          string    // Base URL / 基础地址
+	weight       int       // Weight / 权重
+	current      int       // Current weight of smooth weighted round robin / 平滑加权轮询的当前权重
+	inFlight     int       // Requests in flight / 进行中的请求数
+	failures     int       // Consecutive failures / 连续失败次数
+	ejectedUntil time.Time // End of the current ejection / 当前摘除的结束时间
+}
+
+// ringNode is a point of the consistent hash ring
+// ringNode 是一致性哈希环上的一个节点
+type ringNode struct {
+	hash     uint32    // Position on the ring / 在环上的位置
+	endpoint *endpoint // Owning endpoint / 所属端点
+}
+
+// New creates a balancer from config, failing when there is no endpoint or a base URL is not absolute
+// New 使用 config 创建负载均衡器，没有端点或基础地址不是绝对地址时失败
+func New(config Config) (*Balancer, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, errors.New("load balancer needs at least one endpoint")
+	}
+	if config.MaxFails <= 0 {
+		config.MaxFails = DefaultMaxFails
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultCooldown
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = DefaultFailure
+	}
+
+	b := &Balancer{config: config}
+	for _, e := range config.Endpoints {
+		u, err := url.Parse(e.URL)
+		if err != nil || !u.IsAbs() || u.Host == "" {
+			return nil, fmt.Errorf("endpoint url must be absolute: %s", e.URL)
+		}
+		ep := &endpoint{url: e.URL, weight: max(e.Weight, 1)}
+		b.endpoints = append(b.endpoints, ep)
+		for i := 0; i < virtualNodes*ep.weight; i++ {
+			b.ring = append(b.ring, ringNode{hash: hashKey(e.URL + "#" + strconv.Itoa(i)), endpoint: ep})
+		}
+	}
+	sort.Slice(b.ring, func(i, j int) bool { return b.ring[i].hash < b.ring[j].hash })
+	return b, nil
+}
+
+// Pick chooses the endpoint of an attempt of req. done must be called with the outcome of the attempt;
+// outcomes with canceled errors are not counted.
+// Pick 为 req 的一次尝试选择端点。必须用尝试的结果调用 done；以取消错误结束的结果不计入统计。
+func (b *Balancer) Pick(req *request.Request) (baseURL string, done func(statusCode int, err error)) {
+	var key string
+	if b.config.Strategy == ConsistentHash && b.config.Key != nil {
+		key = b.config.Key(req)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	healthy := make([]*endpoint, 0, len(b.endpoints))
+	for _, ep := range b.endpoints {
+		if !now.Before(ep.ejectedUntil) {
+			healthy = append(healthy, ep)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = b.endpoints
+	}
+
+	var chosen *endpoint
+	switch {
+	case b.config.Strategy == LeastInFlight:
+		chosen = b.leastInFlight(healthy)
+	case b.config.Strategy == Weighted:
+		chosen = smoothWeighted(healthy)
+	case b.config.Strategy == ConsistentHash && key != "":
+		chosen = b.consistentHash(key, healthy)
+	default:
+		chosen = healthy[b.next%len(healthy)]
+		b.next++
+	}
+	chosen.inFlight++
+	return chosen.url, func(statusCode int, err error) {
+		b.done(chosen, statusCode, err)
+	}
+}
+
+// done records the outcome of an attempt sent to ep
+// done 记录发送到 ep 的尝试的结果
+func (b *Balancer) done(ep *endpoint, statusCode int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ep.inFlight--
+	if errors.Is(err, reqerr.ErrCanceled) {
+		return
+	}
+	if !b.config.IsFailure(statusCode, err) {
+		ep.failures = 0
+		return
+	}
+	if ep.failures++; ep.failures >= b.config.MaxFails {
+		ep.failures = 0
+		ep.ejectedUntil = time.Now().Add(b.config.Cooldown)
+	}
+}
+
+// leastInFlight returns the endpoint with the fewest requests in flight, ties are broken in turn
+// leastInFlight 返回进行中请求最少的端点，数量相同时轮流选择
+func (b *Balancer) leastInFlight(healthy []*endpoint) *endpoint {
+	start := b.next % len(healthy)
+	b.next++
+	chosen := healthy[start]
+	for i := 1; i < len(healthy); i++ {
+		if ep := healthy[(start+i)%len(healthy)]; ep.inFlight < chosen.inFlight {
+			chosen = ep
+		}
+	}
+	return chosen
+}
+
+// smoothWeighted implements the smooth weighted round robin used by nginx
+// smoothWeighted 实现 nginx 使用的平滑加权轮询
+func smoothWeighted(healthy []*endpoint) *endpoint {
+	var (
+		total  int
+		chosen *endpoint
+	)
+	for _, ep := range healthy {
+		ep.current += ep.weight
+		total += ep.weight
+		if chosen == nil || ep.current > chosen.current {
+			chosen = ep
+		}
+	}
+	chosen.current -= total
+	return chosen
+}
+
+// consistentHash returns the first healthy endpoint clockwise from the hash of key
+// consistentHash 返回从 key 的哈希值开始顺时针方向的第一个健康端点
+func (b *Balancer) consistentHash(key string, healthy []*endpoint) *endpoint {
+	usable := make(map[*endpoint]bool, len(healthy))
+	for _, ep := range healthy {
+		usable[ep] = true
+	}
+	hash := hashKey(key)
+	start := sort.Search(len(b.ring), func(i int) bool { return b.ring[i].hash >= hash })
+	for i := 0; i < len(b.ring); i++ {
+		if node := b.ring[(start+i)%len(b.ring)]; usable[node.endpoint] {
+			return node.endpoint
+		}
+	}
+	return healthy[0]
+}
+
+// Status returns snapshots of the endpoints in configuration order, for monitoring
+// Status 按配置顺序返回端点的快照，用于监控
+func (b *Balancer) Status() []Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]Status, len(b.endpoints))
+	for i, ep := range b.endpoints {
+		statuses[i] = Status{
+			URL:          ep.url,
+			Weight:       ep.weight,
+			InFlight:     ep.inFlight,
+			Failures:     ep.failures,
+			Ejected:      now.Before(ep.ejectedUntil),
+			EjectedUntil: ep.ejectedUntil,
+		}
+	}
+	return statuses
+}
+
+// hashKey hashes a ring key
+// hashKey 计算环上键的哈希值
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32()
+}
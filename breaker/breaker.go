@@ -0,0 +1,328 @@
+// Package breaker provides per-host circuit breakers for HTTP requests
+// 包 breaker 提供 HTTP 请求的按主机熔断器
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/reqerr"
+)
+
+// Defaults used when a Config field is zero
+// Config 字段为零时使用的默认值
+const (
+	DefaultFailureRatio   = 0.5              // Default failure ratio / 默认失败比例
+	DefaultMinRequests    = 10               // Default minimum number of requests / 默认最小请求数
+	DefaultWindow         = time.Minute      // Default counting window / 默认计数窗口
+	DefaultOpenDuration   = 30 * time.Second // Default open duration / 默认打开时长
+	DefaultHalfOpenProbes = 1                // Default number of half-open probes / 默认半开探测数
+	maxCircuits           = 4096             // Circuits kept before idle closed ones are swept / 清理空闲的关闭状态熔断器前保留的熔断器数
+)
+
+// State is the state of a host's circuit
+// State 是主机熔断器的状态
+type State int
+
+const (
+	StateClosed   State = iota // Requests are sent and outcomes counted / 发送请求并统计结果
+	StateOpen                  // Requests fail fast without being sent / 请求不发送，直接快速失败
+	StateHalfOpen              // A limited number of probe requests decide whether to close again / 由有限数量的探测请求决定是否重新关闭
+)
+
+// String returns the name of the state
+// String 返回状态名称
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Config describes when a host's circuit opens and how it recovers
+// Config 描述主机熔断器何时打开以及如何恢复
+type Config struct {
+	FailureRatio   float64                              // Failure ratio that opens the circuit, DefaultFailureRatio when zero / 使熔断器打开的失败比例，为零时使用 DefaultFailureRatio
+	MinRequests    int                                  // Requests needed in a window before the ratio is checked, DefaultMinRequests when zero / 检查失败比例前窗口内需要的请求数，为零时使用 DefaultMinRequests
+	Window         time.Duration                        // Counts of a closed circuit are reset every window, DefaultWindow when zero / 关闭状态的计数每个窗口重置一次，为零时使用 DefaultWindow
+	OpenDuration   time.Duration                        // Time the circuit stays open before probing, DefaultOpenDuration when zero / 熔断器打开后开始探测前的时间，为零时使用 DefaultOpenDuration
+	HalfOpenProbes int                                  // Successful probes needed to close, also the probes allowed at once, DefaultHalfOpenProbes when zero / 关闭所需的成功探测数，也是同时允许的探测数，为零时使用 DefaultHalfOpenProbes
+	IsFailure      func(statusCode int, err error) bool // Decides whether an attempt failed, DefaultFailure when nil / 判断尝试是否失败，为 nil 时使用 DefaultFailure
+	OnStateChange  func(host string, from, to State)    // Called on every state change, without holding locks / 每次状态变化时调用，调用时不持有锁
+}
+
+// DefaultFailure counts retryable errors (see reqerr.IsRetryable) and 5xx responses as failures
+// DefaultFailure 将可重试错误（见 reqerr.IsRetryable）和 5xx 响应计为失败
+func DefaultFailure(statusCode int, err error) bool {
+	if err != nil {
+		return reqerr.IsRetryable(err)
+	}
+	return statusCode >= 500
+}
+
+// Status is a snapshot of a host's circuit
+// Status 是主机熔断器的快照
+type Status struct {
+	State    State     // Current state / 当前状态
+	Since    time.Time // Time the current state was entered / 进入当前状态的时间
+	Requests int       // Requests counted in the current window or half-open period / 当前窗口或半开期间统计的请求数
+	Failures int       // Failures counted in the current window or half-open period / 当前窗口或半开期间统计的失败数
+}
+
+// Breaker holds one circuit per host. Each attempt of a request, retries included, is one request of the circuit.
+// Breaker 为每个主机维护一个熔断器。请求的每次尝试（包括重试）都是熔断器的一次请求。
+type Breaker struct {
+	config   Config              // Configuration with defaults applied / 应用默认值后的配置
+	circuits map[string]*circuit // Circuits by host / 按主机划分的熔断器
+	mu       sync.Mutex          // Mutex for circuits / 熔断器的互斥锁
+}
+
+// New creates a breaker with config
+// New 使用 config 创建熔断器
+func New(config Config) *Breaker {
+	if config.FailureRatio <= 0 {
+		config.FailureRatio = DefaultFailureRatio
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = DefaultMinRequests
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultWindow
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = DefaultOpenDuration
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = DefaultHalfOpenProbes
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = DefaultFailure
+	}
+	return &Breaker{
+		config:   config,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// Allow reports whether a request to host may be sent. If so, done must be called with the outcome of the request;
+// otherwise the error is of kind reqerr.KindCircuitOpen. Outcomes with canceled errors are not counted.
+// Allow 报告是否可以向 host 发送请求。可以发送时必须用请求结果调用 done；否则返回 reqerr.KindCircuitOpen 类型的错误。
+// 以取消错误结束的结果不计入统计。
+func (b *Breaker) Allow(host string) (done func(statusCode int, err error), err error) {
+	b.mu.Lock()
+	now := time.Now()
+	c, ok := b.circuits[host]
+	if !ok {
+		if len(b.circuits) >= maxCircuits {
+			b.sweepLocked(now)
+		}
+		c = &circuit{since: now, windowStart: now}
+		b.circuits[host] = c
+	}
+	change := b.refresh(host, c, now)
+
+	switch {
+	case c.state == StateOpen:
+		err = reqerr.New(reqerr.KindCircuitOpen, fmt.Sprintf("circuit breaker open for %s, retry in %s",
+			host, c.since.Add(b.config.OpenDuration).Sub(now).Round(time.Millisecond)), nil)
+	case c.state == StateHalfOpen && c.probes >= b.config.HalfOpenProbes:
+		err = reqerr.New(reqerr.KindCircuitOpen, fmt.Sprintf("circuit breaker half-open for %s, probes in flight", host), nil)
+	case c.state == StateHalfOpen:
+		c.probes++
+	}
+	if err == nil {
+		c.inFlight++
+	}
+	generation := c.generation
+	b.mu.Unlock()
+	change.notify(b.config.OnStateChange)
+
+	if err != nil {
+		return nil, err
+	}
+	return func(statusCode int, err error) {
+		b.done(host, c, generation, statusCode, err)
+	}, nil
+}
+
+// done records the outcome of a request allowed in generation
+// done 记录在 generation 代中被允许的请求的结果
+func (b *Breaker) done(host string, c *circuit, generation uint64, statusCode int, err error) {
+	b.mu.Lock()
+	c.inFlight--
+	if c.generation != generation {
+		// The circuit changed state since the request was allowed
+		// 请求被允许后熔断器的状态已经变化
+		b.mu.Unlock()
+		return
+	}
+	if c.state == StateHalfOpen {
+		c.probes--
+	}
+	if errors.Is(err, reqerr.ErrCanceled) {
+		b.mu.Unlock()
+		return
+	}
+
+	var change stateChange
+	c.requests++
+	failed := b.config.IsFailure(statusCode, err)
+	if failed {
+		c.failures++
+	}
+	now := time.Now()
+	switch c.state {
+	case StateClosed:
+		if c.requests >= b.config.MinRequests && float64(c.failures) >= b.config.FailureRatio*float64(c.requests) {
+			change = b.transition(host, c, StateOpen, now)
+		}
+	case StateHalfOpen:
+		if failed {
+			change = b.transition(host, c, StateOpen, now)
+		} else if c.requests >= b.config.HalfOpenProbes {
+			change = b.transition(host, c, StateClosed, now)
+		}
+	}
+	b.mu.Unlock()
+	change.notify(b.config.OnStateChange)
+}
+
+// State returns the current state of host's circuit, StateClosed for unknown hosts
+// State 返回 host 熔断器的当前状态，未知主机返回 StateClosed
+func (b *Breaker) State(host string) State {
+	return b.Status(host).State
+}
+
+// Status returns a snapshot of host's circuit
+// Status 返回 host 熔断器的快照
+func (b *Breaker) Status(host string) Status {
+	b.mu.Lock()
+	c, ok := b.circuits[host]
+	if !ok {
+		b.mu.Unlock()
+		return Status{State: StateClosed}
+	}
+	change := b.refresh(host, c, time.Now())
+	status := c.status()
+	b.mu.Unlock()
+	change.notify(b.config.OnStateChange)
+	return status
+}
+
+// States returns snapshots of the circuits of the hosts seen so far, for monitoring. Once many hosts were seen,
+// closed circuits idle for a whole window are dropped.
+// States 返回目前见过的主机熔断器的快照，用于监控。见过的主机很多时，空闲了整个窗口的关闭状态熔断器会被删除。
+func (b *Breaker) States() map[string]Status {
+	b.mu.Lock()
+	now := time.Now()
+	states := make(map[string]Status, len(b.circuits))
+	var changes []stateChange
+	for host, c := range b.circuits {
+		if change := b.refresh(host, c, now); change.host != "" {
+			changes = append(changes, change)
+		}
+		states[host] = c.status()
+	}
+	b.mu.Unlock()
+	for _, change := range changes {
+		change.notify(b.config.OnStateChange)
+	}
+	return states
+}
+
+// Reset closes host's circuit and clears its counts
+// Reset 关闭 host 的熔断器并清空计数
+func (b *Breaker) Reset(host string) {
+	b.mu.Lock()
+	var change stateChange
+	if c, ok := b.circuits[host]; ok {
+		change = b.transition(host, c, StateClosed, time.Now())
+		if change.from == StateClosed {
+			change = stateChange{}
+		}
+	}
+	b.mu.Unlock()
+	change.notify(b.config.OnStateChange)
+}
+
+// sweepLocked removes closed circuits with no request in flight whose counting window ended,
+// they hold nothing a new circuit would not, so crawlers touching many hosts use bounded memory
+// sweepLocked 删除没有进行中的请求且计数窗口已结束的关闭状态熔断器，它们与新的熔断器没有区别，使访问大量主机的爬虫占用有限的内存
+func (b *Breaker) sweepLocked(now time.Time) {
+	for host, c := range b.circuits {
+		if c.state == StateClosed && c.inFlight == 0 && now.Sub(c.windowStart) >= b.config.Window {
+			delete(b.circuits, host)
+		}
+	}
+}
+
+// refresh applies the time based transitions: window reset while closed, half-open after the open duration
+// refresh 应用基于时间的状态变化：关闭状态下的窗口重置，以及打开时长结束后进入半开状态
+func (b *Breaker) refresh(host string, c *circuit, now time.Time) stateChange {
+	switch c.state {
+	case StateClosed:
+		if now.Sub(c.windowStart) >= b.config.Window {
+			c.requests, c.failures = 0, 0
+			c.windowStart = now
+		}
+	case StateOpen:
+		if now.Sub(c.since) >= b.config.OpenDuration {
+			return b.transition(host, c, StateHalfOpen, now)
+		}
+	}
+	return stateChange{}
+}
+
+// transition moves c to state and clears its counts
+// transition 将 c 切换到 state 并清空计数
+func (b *Breaker) transition(host string, c *circuit, state State, now time.Time) stateChange {
+	change := stateChange{host: host, from: c.state, to: state}
+	c.state = state
+	c.since = now
+	c.windowStart = now
+	c.requests, c.failures, c.probes = 0, 0, 0
+	c.generation++
+	return change
+}
+
+// circuit is the state of one host
+// circuit 是单个主机的熔断状态
+type circuit struct {
+	state       State     // Current state / 当前状态
+	since       time.Time // Time the state was entered / 进入当前状态的时间
+	windowStart time.Time // Start of the counting window / 计数窗口的开始时间
+	requests    int       // Requests counted / 已统计的请求数
+	failures    int       // Failures counted / 已统计的失败数
+	probes      int       // Half-open probes in flight / 进行中的半开探测数
+	inFlight    int       // Allowed requests whose outcome is not recorded yet / 已允许但尚未记录结果的请求数
+	generation  uint64    // Incremented on every transition to ignore stale outcomes / 每次状态变化时递增，用于忽略过期的结果
+}
+
+// status returns a snapshot of c
+// status 返回 c 的快照
+func (c *circuit) status() Status {
+	return Status{State: c.state, Since: c.since, Requests: c.requests, Failures: c.failures}
+}
+
+// stateChange is a transition reported to OnStateChange once the lock is released
+// stateChange 是释放锁后报告给 OnStateChange 的状态变化
+type stateChange struct {
+	host     string // Host of the circuit, empty when nothing changed / 熔断器所属主机，无变化时为空
+	from, to State  // Previous and new state / 之前和新的状态
+}
+
+// notify calls fn if a transition happened
+// notify 在发生状态变化时调用 fn
+func (s stateChange) notify(fn func(host string, from, to State)) {
+	if fn != nil && s.host != "" {
+		fn(s.host, s.from, s.to)
+	}
+}
@@ -0,0 +1,208 @@
+package builder
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BuildRequestURL expands the {name} path placeholders of rawURL with pathParams and merges query into its query string.
+// query can be url.Values, map[string]string, map[string][]string, map[string]interface{} or a struct (or pointer to
+// struct) whose fields are named by `url:"name,omitempty"` tags. Parameters in query replace those of the same name in rawURL
+// and are appended after its other parameters, which are kept exactly as written.
+// BuildRequestURL 使用 pathParams 展开 rawURL 中的 {name} 路径占位符，并将 query 合并到查询字符串中。
+// query 可以是 url.Values、map[string]string、map[string][]string、map[string]interface{}，
+// 或通过 `url:"name,omitempty"` 标签命名字段的结构体（或结构体指针）。query 中的参数会替换 rawURL 中的同名参数，
+// 并追加在其他参数之后，其他参数保持原样。
+func BuildRequestURL(rawURL string, pathParams map[string]string, query interface{}) (string, error) {
+	expanded, err := expandPath(rawURL, pathParams)
+	if err != nil {
+		return "", err
+	}
+
+	values, err := QueryValues(query)
+	if err != nil {
+		return "", err
+	}
+	if len(values) == 0 {
+		return expanded, nil
+	}
+
+	if _, err := url.Parse(expanded); err != nil {
+		return "", err
+	}
+	base, fragment, hasFragment := strings.Cut(expanded, "#")
+	base, rawQuery, _ := strings.Cut(base, "?")
+	var b strings.Builder
+	b.WriteString(base)
+	b.WriteByte('?')
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if _, ok := values[key]; ok {
+			continue
+		}
+		b.WriteString(pair)
+		b.WriteByte('&')
+	}
+	b.WriteString(values.Encode())
+	if hasFragment {
+		b.WriteByte('#')
+		b.WriteString(fragment)
+	}
+	return b.String(), nil
+}
+
+// QueryValues converts query parameters given as a map or tagged struct into url.Values
+// QueryValues 将以 map 或带标签结构体表示的查询参数转换为 url.Values
+func QueryValues(query interface{}) (url.Values, error) {
+	values := url.Values{}
+	switch q := query.(type) {
+	case nil:
+		return values, nil
+	case url.Values:
+		for key, vals := range q {
+			values[key] = append([]string(nil), vals...)
+		}
+		return values, nil
+	case map[string][]string:
+		for key, vals := range q {
+			values[key] = append([]string(nil), vals...)
+		}
+		return values, nil
+	case map[string]string:
+		for key, val := range q {
+			values.Set(key, val)
+		}
+		return values, nil
+	case map[string]interface{}:
+		for key, val := range q {
+			if err := addQueryValue(values, key, reflect.ValueOf(val), false); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+
+	v := reflect.ValueOf(query)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return values, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid query type: %T", query)
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("url"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if err := addQueryValue(values, name, v.Field(i), opts == "omitempty"); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// addQueryValue adds v under key, slices add one value per element
+// addQueryValue 将 v 添加到 key 下，切片的每个元素各添加一个值
+func addQueryValue(values url.Values, key string, v reflect.Value, omitEmpty bool) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || omitEmpty && v.IsZero() {
+		return nil
+	}
+	if v.Kind() == reflect.Array || v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		for i := 0; i < v.Len(); i++ {
+			if err := addQueryValue(values, key, v.Index(i), false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch val := v.Interface().(type) {
+	case time.Time:
+		values.Add(key, val.Format(time.RFC3339))
+		return nil
+	case fmt.Stringer:
+		values.Add(key, val.String())
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		values.Add(key, v.String())
+	case reflect.Bool:
+		values.Add(key, strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values.Add(key, strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values.Add(key, strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		values.Add(key, strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()))
+	case reflect.Slice:
+		values.Add(key, string(v.Bytes()))
+	default:
+		return fmt.Errorf("unsupported query value type for %s: %s", key, v.Type())
+	}
+	return nil
+}
+
+// expandPath replaces the {name} placeholders before the query string of rawURL with escaped path parameters
+// expandPath 将 rawURL 查询字符串之前的 {name} 占位符替换为转义后的路径参数
+func expandPath(rawURL string, pathParams map[string]string) (string, error) {
+	end := strings.IndexAny(rawURL, "?#")
+	if end < 0 {
+		end = len(rawURL)
+	}
+	path, rest := rawURL[:end], rawURL[end:]
+	if !strings.Contains(path, "{") {
+		return rawURL, nil
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			break
+		}
+		stop := strings.IndexByte(path[start:], '}')
+		if stop < 0 {
+			return "", fmt.Errorf("unclosed path parameter in url: %s", rawURL)
+		}
+		name := path[start+1 : start+stop]
+		value, ok := pathParams[name]
+		if !ok {
+			return "", fmt.Errorf("missing path parameter: %s", name)
+		}
+		b.WriteString(path[:start])
+		b.WriteString(url.PathEscape(value))
+		path = path[start+stop+1:]
+	}
+	b.WriteString(path)
+	b.WriteString(rest)
+	return b.String(), nil
+}
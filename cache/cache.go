@@ -0,0 +1,258 @@
+// Package cache provides an RFC 7234 HTTP response cache with pluggable storage
+// 包 cache 提供符合 RFC 7234 的 HTTP 响应缓存，存储方式可插拔
+package cache
+
+import (
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Status tells how the cache took part in a response
+// Status 表示缓存如何参与了一个响应
+type Status int
+
+const (
+	StatusNone        Status = iota // The cache was not used / 未使用缓存
+	StatusMiss                      // Sent to the server, the response may have been stored / 发送到服务器，响应可能已被存储
+	StatusHit                       // Served from the cache without contacting the server / 直接由缓存提供，未联系服务器
+	StatusRevalidated               // Stale stored response confirmed by 304 Not Modified / 过期的已存储响应经 304 Not Modified 确认
+)
+
+// String returns the name of the status
+// String 返回状态名称
+func (s Status) String() string {
+	switch s {
+	case StatusMiss:
+		return "miss"
+	case StatusHit:
+		return "hit"
+	case StatusRevalidated:
+		return "revalidated"
+	default:
+		return "none"
+	}
+}
+
+// Entry is a stored response
+// Entry 是一个已存储的响应
+type Entry struct {
+	URL          string      `json:"url"`          // Request URL / 请求地址
+	StatusCode   int         `json:"statusCode"`   // Response status code / 响应状态码
+	Proto        string      `json:"proto"`        // Response protocol / 响应协议
+	Header       http.Header `json:"header"`       // Response headers / 响应头
+	Body         []byte      `json:"body"`         // Response body / 响应体
+	Vary         http.Header `json:"vary"`         // Request headers named by Vary when stored / 存储时 Vary 指定的请求头
+	RequestTime  time.Time   `json:"requestTime"`  // Time the request was sent / 发送请求的时间
+	ResponseTime time.Time   `json:"responseTime"` // Time the response was received / 收到响应的时间
+}
+
+// Age returns the current age of the stored response at now
+// Age 返回已存储响应在 now 时刻的当前年龄
+func (e *Entry) Age(now time.Time) time.Duration {
+	return currentAge(e, now)
+}
+
+// Store keeps entries by key. Implementations must be safe for concurrent use and must not modify stored entries.
+// Store 按键保存条目。实现必须是并发安全的，且不得修改已存储的条目。
+type Store interface {
+	// Get returns the entry stored under key
+	// Get 返回 key 下存储的条目
+	Get(key string) (*Entry, bool)
+
+	// Set stores entry under key, replacing any previous entry
+	// Set 将 entry 存储在 key 下，替换之前的条目
+	Set(key string, entry *Entry)
+
+	// Delete removes the entry stored under key
+	// Delete 删除 key 下存储的条目
+	Delete(key string)
+}
+
+// Cache decides, following RFC 7234, which GET responses are stored, served or revalidated
+// Cache 按照 RFC 7234 决定哪些 GET 响应被存储、直接提供或重新验证
+type Cache struct {
+	Store  Store // Entry storage / 条目存储
+	Shared bool  // Act as a shared cache: honor s-maxage and private, skip requests with Authorization / 作为共享缓存：遵循 s-maxage 和 private，跳过带 Authorization 的请求
+}
+
+// New creates a private cache, the kind used by a single client, backed by store
+// New 创建一个由 store 支持的私有缓存（单个客户端使用的缓存）
+func New(store Store) *Cache {
+	return &Cache{Store: store}
+}
+
+// Lookup returns the stored response for a request of method to rawURL sent with header.
+// fresh reports whether it may be served without contacting the server, otherwise a non-nil entry
+// has to be revalidated with the headers returned by Validators.
+// Lookup 返回以 header 请求头发往 rawURL 的 method 请求对应的已存储响应。
+// fresh 表示是否可以不联系服务器直接提供，否则非 nil 的条目需要使用 Validators 返回的请求头重新验证。
+func (c *Cache) Lookup(method, rawURL string, header http.Header, now time.Time) (entry *Entry, fresh bool) {
+	if c.bypass(method, header) {
+		return nil, false
+	}
+	entry, ok := c.Store.Get(rawURL)
+	if !ok || entry.URL != rawURL || !varyMatches(entry, header) {
+		return nil, false
+	}
+
+	reqCC, respCC := parseControl(header), parseControl(entry.Header)
+	if reqCC.has("no-cache") || respCC.has("no-cache") {
+		return entry, false
+	}
+	lifetime, age := freshnessLifetime(entry, c.Shared), entry.Age(now)
+	if maxAge, ok := reqCC.seconds("max-age"); ok && age > maxAge {
+		return entry, false
+	}
+	if minFresh, ok := reqCC.seconds("min-fresh"); ok && lifetime-age < minFresh {
+		return entry, false
+	}
+	if lifetime > age {
+		return entry, true
+	}
+
+	// Serve stale responses only when the request allows it and the server does not forbid it
+	// 仅当请求允许且服务器未禁止时才提供过期响应
+	if !reqCC.has("max-stale") || respCC.has("must-revalidate") || (c.Shared && respCC.has("proxy-revalidate")) {
+		return entry, false
+	}
+	if maxStale, ok := reqCC.seconds("max-stale"); ok && age-lifetime > maxStale {
+		return entry, false
+	}
+	return entry, true
+}
+
+// OnlyIfCached reports whether the request asked to be answered from the cache only (Cache-Control: only-if-cached)
+// OnlyIfCached 报告请求是否要求只由缓存回答（Cache-Control: only-if-cached）
+func OnlyIfCached(header http.Header) bool {
+	return parseControl(header).has("only-if-cached")
+}
+
+// Validators returns the conditional request headers revalidating entry, nil if it has no validator
+// Validators 返回重新验证 entry 的条件请求头，条目没有校验器时返回 nil
+func (c *Cache) Validators(entry *Entry) http.Header {
+	header := make(http.Header)
+	if etag := entry.Header.Get("ETag"); etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+	if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+		header.Set("If-Modified-Since", lastModified)
+	}
+	if len(header) == 0 {
+		return nil
+	}
+	return header
+}
+
+// Put stores entry, the response to a request of method sent with header, if RFC 7234 allows it.
+// It reports whether the entry was stored.
+// Put 在 RFC 7234 允许时存储 entry（以 header 请求头发送的 method 请求的响应），并报告是否已存储。
+func (c *Cache) Put(method string, header http.Header, entry *Entry) bool {
+	if c.bypass(method, header) || entry.StatusCode == http.StatusNotModified || entry.StatusCode == http.StatusPartialContent {
+		return false
+	}
+	reqCC, respCC := parseControl(header), parseControl(entry.Header)
+	switch {
+	case reqCC.has("no-store"), respCC.has("no-store"):
+		return false
+	case c.Shared && respCC.has("private"):
+		return false
+	case c.Shared && header.Get("Authorization") != "" &&
+		!respCC.has("public") && !respCC.has("must-revalidate") && !respCC.has("s-maxage"):
+		return false
+	case !heuristicallyCacheable[entry.StatusCode] && !respCC.has("public") &&
+		!hasExplicitExpiration(entry.Header, respCC, c.Shared):
+		return false
+	}
+
+	// Remember the request headers the response varies on
+	// 记住响应所依赖的请求头
+	for _, field := range entry.Header.Values("Vary") {
+		for _, name := range strings.Split(field, ",") {
+			name = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))
+			if name == "*" {
+				return false
+			}
+			if name != "" {
+				if entry.Vary == nil {
+					entry.Vary = make(http.Header)
+				}
+				entry.Vary[name] = header.Values(name)
+			}
+		}
+	}
+	c.Store.Set(entry.URL, entry)
+	return true
+}
+
+// Revalidate updates entry with the headers of a 304 Not Modified response received between requestTime and
+// responseTime, stores and returns the updated entry (RFC 7234 section 4.3.4)
+// Revalidate 使用在 requestTime 和 responseTime 之间收到的 304 Not Modified 响应头更新 entry，
+// 存储并返回更新后的条目（RFC 7234 第 4.3.4 节）
+func (c *Cache) Revalidate(entry *Entry, header http.Header, requestTime, responseTime time.Time) *Entry {
+	updated := *entry
+	updated.Header = entry.Header.Clone()
+	for key, values := range header {
+		switch key {
+		case "Content-Length", "Transfer-Encoding", "Connection", "Keep-Alive":
+			continue
+		}
+		updated.Header[key] = values
+	}
+	updated.RequestTime, updated.ResponseTime = requestTime, responseTime
+	c.Store.Set(updated.URL, &updated)
+	return &updated
+}
+
+// Invalidate removes the stored response of rawURL after a successful unsafe request (RFC 7234 section 4.4)
+// Invalidate 在不安全方法的请求成功后删除 rawURL 的已存储响应（RFC 7234 第 4.4 节）
+func (c *Cache) Invalidate(method, rawURL string, statusCode int) {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return
+	}
+	if statusCode >= 200 && statusCode < 400 {
+		c.Store.Delete(rawURL)
+	}
+}
+
+// bypass reports whether the cache stays out of a request: only plain GET requests are cached,
+// conditional and range requests made by the caller are sent as is
+// bypass 报告缓存是否不参与该请求：只缓存普通的 GET 请求，调用方自己发起的条件请求和范围请求按原样发送
+func (c *Cache) bypass(method string, header http.Header) bool {
+	if method != http.MethodGet {
+		return true
+	}
+	for _, key := range []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since", "If-Range", "Range"} {
+		if header.Get(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// varyMatches reports whether header selects the same variant as the request entry was stored for
+// varyMatches 报告 header 是否与存储 entry 时的请求选择相同的变体
+func varyMatches(entry *Entry, header http.Header) bool {
+	for name, values := range entry.Vary {
+		if normalizeField(values) != normalizeField(header.Values(name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeField joins the values of a header field, dropping optional whitespace
+// normalizeField 合并请求头字段的值，并去除可选的空白
+func normalizeField(values []string) string {
+	var parts []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// heuristicFraction is the fraction of the time since Last-Modified used as heuristic freshness (RFC 7234 section 4.2.2)
+// heuristicFraction 是启发式新鲜度使用的自 Last-Modified 以来时间的比例（RFC 7234 第 4.2.2 节）
+const heuristicFraction = 10
+
+// heuristicallyCacheable are the status codes cacheable by default (RFC 7231 section 6.1)
+// heuristicallyCacheable 是默认可缓存的状态码（RFC 7231 第 6.1 节）
+var heuristicallyCacheable = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// control holds the directives of Cache-Control headers, valueless directives map to ""
+// control 保存 Cache-Control 请求头的指令，无值的指令映射为 ""
+type control map[string]string
+
+// parseControl parses the Cache-Control header of header, including the legacy "Pragma: no-cache"
+// parseControl 解析 header 中的 Cache-Control 请求头，包括旧的 "Pragma: no-cache"
+func parseControl(header http.Header) control {
+	cc := make(control)
+	for _, line := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				cc[name] = strings.Trim(strings.TrimSpace(value), `"`)
+			}
+		}
+	}
+	if _, ok := header["Cache-Control"]; !ok && strings.Contains(strings.ToLower(header.Get("Pragma")), "no-cache") {
+		cc["no-cache"] = ""
+	}
+	return cc
+}
+
+// has reports whether the directive is present
+// has 报告指令是否存在
+func (cc control) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+// seconds returns the value of a delta-seconds directive
+// seconds 返回 delta-seconds 指令的值
+func (cc control) seconds(name string) (time.Duration, bool) {
+	value, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// freshnessLifetime returns how long a response stays fresh (RFC 7234 section 4.2.1)
+// freshnessLifetime 返回响应保持新鲜的时长（RFC 7234 第 4.2.1 节）
+func freshnessLifetime(entry *Entry, shared bool) time.Duration {
+	cc := parseControl(entry.Header)
+	if shared {
+		if lifetime, ok := cc.seconds("s-maxage"); ok {
+			return lifetime
+		}
+	}
+	if lifetime, ok := cc.seconds("max-age"); ok {
+		return lifetime
+	}
+	date := responseDate(entry)
+	if expires := entry.Header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil || !t.After(date) {
+			return 0
+		}
+		return t.Sub(date)
+	}
+	if lastModified, err := http.ParseTime(entry.Header.Get("Last-Modified")); err == nil &&
+		heuristicallyCacheable[entry.StatusCode] && date.After(lastModified) {
+		return date.Sub(lastModified) / heuristicFraction
+	}
+	return 0
+}
+
+// currentAge returns the age of a stored response at now (RFC 7234 section 4.2.3)
+// currentAge 返回已存储响应在 now 时刻的年龄（RFC 7234 第 4.2.3 节）
+func currentAge(entry *Entry, now time.Time) time.Duration {
+	apparentAge := max(0, entry.ResponseTime.Sub(responseDate(entry)))
+	var ageValue time.Duration
+	if age, err := strconv.ParseInt(entry.Header.Get("Age"), 10, 64); err == nil && age > 0 {
+		ageValue = time.Duration(age) * time.Second
+	}
+	correctedAgeValue := ageValue + entry.ResponseTime.Sub(entry.RequestTime)
+	correctedInitialAge := max(apparentAge, correctedAgeValue)
+	return correctedInitialAge + max(0, now.Sub(entry.ResponseTime))
+}
+
+// responseDate returns the Date header of entry, the response time when missing
+// responseDate 返回 entry 的 Date 响应头，缺失时返回响应时间
+func responseDate(entry *Entry) time.Time {
+	if date, err := http.ParseTime(entry.Header.Get("Date")); err == nil {
+		return date
+	}
+	return entry.ResponseTime
+}
+
+// hasExplicitExpiration reports whether header states how long the response is fresh
+// hasExplicitExpiration 报告 header 是否声明了响应的新鲜时长
+func hasExplicitExpiration(header http.Header, cc control, shared bool) bool {
+	return header.Get("Expires") != "" || cc.has("max-age") || (shared && cc.has("s-maxage"))
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DiskStore stores every entry as a JSON file in a directory, so entries survive restarts and can be shared by processes.
+// Write errors are ignored, the cache then behaves as if the entry was not stored.
+// DiskStore 将每个条目作为 JSON 文件存储在目录中，条目在重启后仍然保留，并可在进程间共享。
+// 写入错误会被忽略，此时缓存的行为与未存储该条目相同。
+type DiskStore struct {
+	dir string // Directory holding the entries / 保存条目的目录
+}
+
+// NewDiskStore creates a store in dir, creating the directory if needed
+// NewDiskStore 在 dir 中创建存储，目录不存在时会创建
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache directory error: %w", err)
+	}
+	return &DiskStore{dir: dir}, nil
+}
+
+// Get returns the entry stored under key
+// Get 返回 key 下存储的条目
+func (s *DiskStore) Get(key string) (*Entry, bool) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+	entry := &Entry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Set stores entry under key, writing a temporary file first so readers never see a partial entry
+// Set 将 entry 存储在 key 下，先写入临时文件，读取方不会看到不完整的条目
+func (s *DiskStore) Set(key string, entry *Entry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	file, err := os.CreateTemp(s.dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), s.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+	}
+}
+
+// Delete removes the entry stored under key
+// Delete 删除 key 下存储的条目
+func (s *DiskStore) Delete(key string) {
+	_ = os.Remove(s.path(key))
+}
+
+// path returns the file of key
+// path 返回 key 对应的文件
+func (s *DiskStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// MemoryStore is an in-memory store evicting the least recently used entries
+// MemoryStore 是淘汰最近最少使用条目的内存存储
+type MemoryStore struct {
+	maxEntries int                      // Maximum number of entries, 0 means unlimited / 最大条目数，0 表示不限制
+	maxBytes   int64                    // Maximum total body size, 0 means unlimited / 响应体总大小上限，0 表示不限制
+	size       int64                    // Current total body size / 当前响应体总大小
+	order      *list.List               // Entries from most to least recently used / 从最近到最久使用排列的条目
+	items      map[string]*list.Element // List elements by key / 按键索引的链表元素
+	mu         sync.Mutex               // Mutex for the entries / 条目的互斥锁
+}
+
+// memoryItem is a list element of a MemoryStore
+// memoryItem 是 MemoryStore 的链表元素
+type memoryItem struct {
+	key   string // Entry key / 条目键
+	entry *Entry // Stored entry / 已存储的条目
+}
+
+// NewMemoryStore creates an LRU store holding at most maxEntries entries and maxBytes of bodies, 0 means unlimited
+// NewMemoryStore 创建一个最多保存 maxEntries 个条目、响应体总计 maxBytes 字节的 LRU 存储，0 表示不限制
+func NewMemoryStore(maxEntries int, maxBytes int64) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry stored under key and marks it as recently used
+// Get 返回 key 下存储的条目，并将其标记为最近使用
+func (s *MemoryStore) Get(key string) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(elem)
+	return elem.Value.(*memoryItem).entry, true
+}
+
+// Set stores entry under key, evicting the least recently used entries when over the limits.
+// Entries larger than maxBytes are not stored.
+// Set 将 entry 存储在 key 下，超出限制时淘汰最近最少使用的条目。大于 maxBytes 的条目不会被存储。
+func (s *MemoryStore) Set(key string, entry *Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(key)
+	if s.maxBytes > 0 && int64(len(entry.Body)) > s.maxBytes {
+		return
+	}
+	s.items[key] = s.order.PushFront(&memoryItem{key: key, entry: entry})
+	s.size += int64(len(entry.Body))
+	for (s.maxEntries > 0 && s.order.Len() > s.maxEntries) || (s.maxBytes > 0 && s.size > s.maxBytes) {
+		s.remove(s.order.Back().Value.(*memoryItem).key)
+	}
+}
+
+// Delete removes the entry stored under key
+// Delete 删除 key 下存储的条目
+func (s *MemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(key)
+}
+
+// Len returns the number of stored entries
+// Len 返回已存储的条目数
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+// remove removes the entry of key, the caller holds the lock
+// remove 删除 key 的条目，调用方需持有锁
+func (s *MemoryStore) remove(key string) {
+	if elem, ok := s.items[key]; ok {
+		s.order.Remove(elem)
+		delete(s.items, key)
+		s.size -= int64(len(elem.Value.(*memoryItem).entry.Body))
+	}
+}
@@ -0,0 +1,255 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/auth"
+	"github.com/GoEnthusiast/httpreq/balancer"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/transportsetting"
+	"github.com/GoEnthusiast/httpreq/types/request"
+	"github.com/GoEnthusiast/httpreq/types/response"
+)
+
+// outgoing holds the parts of a request that are built once and replayed by every attempt
+// outgoing 保存只构建一次、每次尝试重复使用的请求部分
+type outgoing struct {
+	url         string      // Request URL with path and query parameters applied / 应用了路径参数和查询参数的请求地址
+	host        string      // Host of url / url 的主机
+	header      http.Header // Extra headers such as cache validators / 额外的请求头，例如缓存校验请求头
+	body        []byte      // Buffered request body / 缓存的请求体
+	contentType string      // Content-Type of the body / 请求体的 Content-Type
+
+	balancer *balancer.Balancer // Load balancer choosing the endpoint of each attempt, nil when not used / 为每次尝试选择端点的负载均衡器，未使用时为 nil
+	endpoint string             // Endpoint chosen for the attempt / 为该尝试选择的端点
+}
+
+// attemptStartKey is the context key for the start of an attempt, the rate limiter wait included
+// attemptStartKey 是尝试开始时间（包括等待限流器的时间）的上下文键
+type attemptStartKey struct{}
+
+// withAttemptStart returns a copy of ctx recording that an attempt starts now
+// withAttemptStart 返回记录尝试从现在开始的 ctx 副本
+func withAttemptStart(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptStartKey{}, time.Now())
+}
+
+// attemptDeadline returns when the total timeout of the attempt started in ctx expires,
+// so the rate limiter wait and every hedged copy share one deadline
+// attemptDeadline 返回在 ctx 中开始的尝试的总超时到期时间，使等待限流器和每个对冲副本共享同一个截止时间
+func attemptDeadline(ctx context.Context, timeout time.Duration) time.Time {
+	start, ok := ctx.Value(attemptStartKey{}).(time.Time)
+	if !ok {
+		start = time.Now()
+	}
+	return start.Add(timeout)
+}
+
+// doAttempt sends one attempt of req and reads its response.
+// The returned bool reports whether retrying can help, e.g. false when the request never reached the transport.
+// doAttempt 发送 req 的一次尝试并读取响应。返回的 bool 表示重试是否有意义，例如请求未到达传输层时为 false。
+func (h *RequestHandler) doAttempt(ctx, reqCtx context.Context, req *request.Request, out *outgoing) (*response.Response, bool) {
+	resp := &response.Response{
+		Request:   req,
+		StartTime: time.Now(),
+	}
+
+	// Release the attempt's contexts when done, unless a streamed body takes them over
+	// 尝试结束时释放其上下文，除非由流式响应体接管
+	var (
+		cleanups  []func()
+		handedOff bool
+		trace     = newPhaseTrace(resp.StartTime) // Connection phase trace / 连接阶段追踪
+	)
+	defer func() {
+		if !handedOff {
+			resp.EndTime = time.Now()
+			resp.Timing = trace.snapshot(resp.EndTime)
+			runCleanups(cleanups)
+		}
+	}()
+
+	// Apply timeouts to this attempt only instead of the shared client
+	// 仅对当前尝试应用超时，而不是修改共享的客户端
+	timeout, headerTimeout := h.requestTimeouts(req)
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		reqCtx, cancelTimeout = context.WithDeadlineCause(reqCtx, attemptDeadline(reqCtx, timeout), timeoutError("total", timeout))
+		cleanups = append(cleanups, cancelTimeout)
+	}
+	reqCtx = transportsetting.WithConnectTimeout(reqCtx, req.ConnectTimeout)
+	reqCtx = auth.WithTransport(reqCtx, h.TransportSetting)
+	reqCtx = transportsetting.WithProxyRecorder(reqCtx, func(proxyURL *url.URL) {
+		resp.Proxy = ""
+		if proxyURL != nil {
+			resp.Proxy = proxyURL.Redacted()
+		}
+	})
+	reqCtx, cancelReq := context.WithCancelCause(reqCtx)
+	cleanups = append(cleanups, func() { cancelReq(nil) })
+
+	// Bind the redirect policy and record the redirects followed by this attempt
+	// 绑定重定向策略并记录本次尝试跟随的重定向
+	redirects := &redirectState{policy: h.requestRedirectPolicy(req)}
+	reqCtx = withRedirectState(reqCtx, redirects)
+
+	// Trace the connection phases of this attempt
+	// 追踪本次尝试的连接各阶段
+	reqCtx = httptrace.WithClientTrace(reqCtx, trace.clientTrace())
+
+	// Create HTTP request with a fresh body reader
+	// 使用新的请求体读取器创建 HTTP 请求
+	var bodyReader io.Reader
+	if out.body != nil {
+		bodyReader = bytes.NewReader(out.body)
+	}
+	httpReq, err := http.NewRequestWithContext(reqCtx, string(req.Method), out.url, bodyReader)
+	if err != nil {
+		resp.Error = reqerr.New(reqerr.KindInvalidRequest, "new http request error", err)
+		return resp, false
+	}
+
+	// Set request headers on a copy, so the caller's header map is never modified
+	// 在副本上设置请求头，调用方的请求头不会被修改
+	httpReq.Header = h.requestHeader(req.Header)
+	for key, values := range out.header {
+		httpReq.Header[key] = values
+	}
+
+	// Set content-type header
+	// 设置 content-type 头部
+	if out.contentType != "" {
+		httpReq.Header.Set("Content-Type", out.contentType)
+	}
+
+	// Add credentials of the authentication provider
+	// 添加认证提供者的认证信息
+	provider := h.requestAuth(req)
+	if provider != nil {
+		if authE := provider.Authorize(httpReq); authE != nil {
+			resp.Error = authError(ctx, reqCtx, authE)
+			return resp, true
+		}
+	}
+
+	// Run before-request hooks
+	// 执行请求前钩子
+	if hookE := h.interceptors.BeforeRequest(req, httpReq); hookE != nil {
+		resp.Error = reqerr.New(reqerr.KindInvalidRequest, "before request hook error", hookE)
+		return resp, false
+	}
+
+	// Sign the request as it is sent, including its final headers and body
+	// 按实际发送的形式对请求签名，包括最终的请求头和请求体
+	reqSigner := h.requestSigner(req)
+	if reqSigner != nil {
+		if signE := reqSigner.Sign(httpReq, out.body); signE != nil {
+			resp.Error = reqerr.New(reqerr.KindAuth, "sign request error", signE)
+			return resp, false
+		}
+	}
+
+	// Execute HTTP request, aborting if the response header does not arrive in time
+	// 执行 HTTP 请求，响应头未能及时到达时中止
+	var headerTimer *time.Timer
+	if headerTimeout > 0 {
+		headerTimer = time.AfterFunc(headerTimeout, func() {
+			cancelReq(timeoutError("response header", headerTimeout))
+		})
+	}
+	httpResp, err := h.client.Do(httpReq)
+	if err == nil && provider != nil && httpResp.StatusCode == http.StatusUnauthorized && provider.Challenge(httpReq, httpResp) {
+		// Answer the authentication challenge by sending the request once more
+		// 通过再发送一次请求来响应认证质询
+		drainBody(httpResp.Body)
+		redirects.hops = nil
+		if httpReq, err = reauthorize(httpReq, provider, reqSigner, out.body); err != nil {
+			err = authError(ctx, reqCtx, err)
+		} else {
+			httpResp, err = h.client.Do(httpReq)
+		}
+	}
+	if headerTimer != nil {
+		headerTimer.Stop()
+	}
+	resp.Redirects = redirects.hops
+	if err != nil {
+		if ctxE := contextError(ctx, reqCtx); ctxE != nil {
+			resp.Error = ctxE
+			return resp, true
+		}
+		resp.Error = reqerr.Classify("do http request error", err)
+		return resp, true
+	}
+
+	// Record response metadata
+	// 记录响应元数据
+	resp.ResponseStatusCode = httpResp.StatusCode
+	resp.Header = httpResp.Header
+	resp.Cookies = httpResp.Cookies()
+	resp.Proto = httpResp.Proto
+	resp.ContentLength = httpResp.ContentLength
+	resp.TLS = httpResp.TLS
+	resp.FinalURL = httpResp.Request.URL.String()
+
+	// Hand the body to the caller in streaming mode, timing is recorded once it is consumed
+	// 流式模式下将响应体交给调用方，读取完毕后记录耗时
+	maxBodySize := h.requestMaxBodySize(req)
+	if req.StreamBody {
+		handedOff = true
+		resp.Timing = trace.snapshot(time.Time{})
+		resp.BodyReader = newBodyReader(httpResp, resp, trace, maxBodySize, cleanups)
+		return resp, true
+	}
+	// Closing an unfinished body also closes the connection
+	// 关闭未读完的响应体同时会关闭连接
+	defer httpResp.Body.Close()
+
+	// Read response body, into the sink for successful downloads or into memory otherwise
+	// 读取响应体，成功的下载写入目标位置，否则读入内存
+	respBody := limitBody(httpResp.Body, maxBodySize)
+	retryable := true
+	if hasBodySink(req) && httpResp.StatusCode >= 200 && httpResp.StatusCode < 300 {
+		resp.BodySize, err = writeBodySink(req, respBody)
+		// Bytes that reached BodyWriter cannot be taken back and a retry would write them again,
+		// only an output file is created again by the next attempt
+		// 已写入 BodyWriter 的字节无法撤回，重试会再次写入，只有输出文件会在下一次尝试中重新创建
+		retryable = req.BodyWriter == nil || resp.BodySize == 0
+	} else {
+		resp.ResponseBody, err = io.ReadAll(respBody)
+		resp.BodySize = int64(len(resp.ResponseBody))
+	}
+	if errors.Is(err, ErrBodyTooLarge) {
+		if !req.KeepTruncatedBody {
+			resp.ResponseBody = nil
+		}
+		resp.Error = err
+		return resp, false
+	}
+	if err != nil {
+		if ctxE := contextError(ctx, reqCtx); ctxE != nil {
+			resp.Error = ctxE
+			return resp, retryable
+		}
+		resp.Error = reqerr.New(reqerr.KindBodyRead, "read response body error", err)
+		return resp, retryable
+	}
+
+	resp.Trailer = httpResp.Trailer
+	return resp, true
+}
+
+// runCleanups runs cleanup functions in reverse order
+// runCleanups 按相反顺序执行清理函数
+func runCleanups(cleanups []func()) {
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
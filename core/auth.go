@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/GoEnthusiast/httpreq/auth"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/signer"
+)
+
+// maxDrainSize is the most bytes read from a discarded body to keep its connection reusable
+// maxDrainSize 是为了复用连接而从丢弃的响应体中读取的最大字节数
+const maxDrainSize = 64 << 10
+
+// reauthorize returns a copy of httpReq with a fresh body and the provider's current credentials, signed again if needed
+// reauthorize 返回带有新请求体和提供者当前认证信息的 httpReq 副本，需要时重新签名
+func reauthorize(httpReq *http.Request, provider auth.Provider, reqSigner signer.Signer, body []byte) (*http.Request, error) {
+	next := httpReq.Clone(httpReq.Context())
+	if httpReq.GetBody != nil {
+		rc, err := httpReq.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = rc
+	}
+	next.Header.Del("Authorization")
+	if err := provider.Authorize(next); err != nil {
+		return nil, err
+	}
+	if reqSigner != nil {
+		if err := reqSigner.Sign(next, body); err != nil {
+			return nil, reqerr.New(reqerr.KindAuth, "sign request error", err)
+		}
+	}
+	return next, nil
+}
+
+// authError classifies an error of an authentication provider, preferring the reason the request context ended
+// authError 对认证提供者的错误进行分类，优先报告请求上下文结束的原因
+func authError(ctx, reqCtx context.Context, err error) error {
+	if ctxE := contextError(ctx, reqCtx); ctxE != nil {
+		return ctxE
+	}
+	if _, ok := err.(*reqerr.Error); ok {
+		return err
+	}
+	return reqerr.New(reqerr.KindAuth, "authorize request error", err)
+}
+
+// drainBody reads a bounded amount of body and closes it
+// drainBody 读取有限数量的响应体后将其关闭
+func drainBody(body io.ReadCloser) {
+	_, _ = io.CopyN(io.Discard, body, maxDrainSize)
+	_ = body.Close()
+}
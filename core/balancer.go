@@ -0,0 +1,49 @@
+package core
+
+import (
+	"github.com/GoEnthusiast/httpreq/balancer"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// requestBalancer returns the load balancer choosing the endpoints of rawURL, nil when none is set or rawURL is absolute
+// requestBalancer 返回为 rawURL 选择端点的负载均衡器，未设置或 rawURL 为绝对地址时返回 nil
+func (h *RequestHandler) requestBalancer(rawURL string) *balancer.Balancer {
+	h.mu.RLock()
+	b := h.balancer
+	h.mu.RUnlock()
+	if b == nil || !isRelativeURL(rawURL) {
+		return nil
+	}
+	return b
+}
+
+// pickEndpoint returns out sent to the endpoint the load balancer chose for an attempt of req,
+// done records the attempt's outcome. Without a load balancer out is returned unchanged.
+// pickEndpoint 返回发往负载均衡器为 req 的一次尝试所选端点的 out，done 记录该尝试的结果。没有负载均衡器时原样返回 out。
+func pickEndpoint(req *request.Request, out *outgoing) (target *outgoing, done func(statusCode int, err error), err error) {
+	if out.balancer == nil {
+		return out, func(int, error) {}, nil
+	}
+	baseURL, done := out.balancer.Pick(req)
+	target = new(outgoing)
+	*target = *out
+	target.endpoint = baseURL
+	if target.url, err = joinURL(baseURL, out.url); err == nil {
+		target.host, err = urlHost(target.url)
+	}
+	if err != nil {
+		done(0, nil)
+		return nil, nil, err
+	}
+	return target, done, nil
+}
+
+// SetLoadBalancer sets the load balancer spreading requests with relative URLs over upstream endpoints.
+// It takes precedence over the base URL, nil disables it.
+// SetLoadBalancer 设置将相对地址请求分散到各个上游端点的负载均衡器，优先于基础地址，nil 表示禁用
+func (h *RequestHandler) SetLoadBalancer(b *balancer.Balancer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.balancer = b
+}
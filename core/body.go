@@ -0,0 +1,146 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/types/request"
+	"github.com/GoEnthusiast/httpreq/types/response"
+)
+
+// bodyReader wraps a streamed response body and records timing once it is fully consumed or closed
+// bodyReader 包装流式响应体，在完全读取或关闭时记录耗时
+type bodyReader struct {
+	body     io.ReadCloser      // Underlying response body / 底层响应体
+	reader   io.Reader          // Size limited view of body / 限制大小后的响应体
+	httpResp *http.Response     // Underlying HTTP response / 底层 HTTP 响应
+	resp     *response.Response // Response to update / 需要更新的响应
+	trace    *phaseTrace        // Phase trace of the attempt / 尝试的阶段追踪
+	cleanups []func()           // Context releases taken over from the attempt / 从尝试中接管的上下文释放函数
+	once     sync.Once          // Ensures finish runs once / 确保 finish 只执行一次
+}
+
+// newBodyReader creates a streamed body reader that takes over the attempt's cleanups
+// newBodyReader 创建一个接管尝试清理函数的流式响应体读取器
+func newBodyReader(httpResp *http.Response, resp *response.Response, trace *phaseTrace, maxBodySize int64, cleanups []func()) *bodyReader {
+	return &bodyReader{
+		body:     httpResp.Body,
+		reader:   limitBody(httpResp.Body, maxBodySize),
+		httpResp: httpResp,
+		resp:     resp,
+		trace:    trace,
+		cleanups: cleanups,
+	}
+}
+
+// Read reads from the response body, finishing the response at EOF
+// Read 读取响应体，读到 EOF 时完成响应
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	b.resp.BodySize += int64(n)
+	if errors.Is(err, ErrBodyTooLarge) {
+		b.resp.Error = err
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+// Close closes the response body and finishes the response
+// Close 关闭响应体并完成响应
+func (b *bodyReader) Close() error {
+	err := b.body.Close()
+	b.finish()
+	return err
+}
+
+// finish records the end time, duration, timing and trailers, then releases the attempt's contexts
+// finish 记录结束时间、耗时、阶段耗时和尾部字段，然后释放尝试的上下文
+func (b *bodyReader) finish() {
+	b.once.Do(func() {
+		b.resp.EndTime = time.Now()
+		b.resp.Duration = b.resp.EndTime.Sub(b.resp.StartTime).Seconds()
+		b.resp.Timing = b.trace.snapshot(b.resp.EndTime)
+		b.resp.Trailer = b.httpResp.Trailer
+		runCleanups(b.cleanups)
+	})
+}
+
+// limitedBody fails with ErrBodyTooLarge once more than limit bytes are available
+// limitedBody 在可读字节超过 limit 时返回 ErrBodyTooLarge
+type limitedBody struct {
+	reader    io.Reader // Underlying reader / 底层读取器
+	limit     int64     // Maximum number of bytes / 最大字节数
+	remaining int64     // Bytes still allowed / 剩余允许读取的字节数
+}
+
+// limitBody wraps body so reading more than maxBodySize bytes fails, 0 means unlimited
+// limitBody 包装 body，读取超过 maxBodySize 字节时失败，0 表示不限制
+func limitBody(body io.Reader, maxBodySize int64) io.Reader {
+	if maxBodySize <= 0 {
+		return body
+	}
+	return &limitedBody{reader: body, limit: maxBodySize, remaining: maxBodySize}
+}
+
+// Read reads at most the remaining allowance, probing one extra byte to detect an oversized body
+// Read 最多读取剩余允许的字节数，并多探测一个字节以判断响应体是否超限
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if l.remaining <= 0 {
+		var probe [1]byte
+		for {
+			n, err := l.reader.Read(probe[:])
+			if n > 0 {
+				return 0, reqerr.New(reqerr.KindTooLarge, fmt.Sprintf("response body too large: limit %d bytes", l.limit), nil)
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// hasBodySink reports whether req downloads its response body into a writer or file
+// hasBodySink 报告 req 是否将响应体下载到写入器或文件中
+func hasBodySink(req *request.Request) bool {
+	return req.BodyWriter != nil || req.OutputFile != ""
+}
+
+// writeBodySink copies body into the request's writer or output file and returns the number of bytes written
+// writeBodySink 将 body 复制到请求的写入器或输出文件中，并返回写入的字节数
+func writeBodySink(req *request.Request, body io.Reader) (int64, error) {
+	if req.BodyWriter != nil {
+		return io.Copy(req.BodyWriter, body)
+	}
+
+	file, err := os.Create(req.OutputFile)
+	if err != nil {
+		return 0, fmt.Errorf("create output file error: %w", err)
+	}
+	n, err := io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	// Drop a truncated download unless the caller asked to keep it
+	// 除非调用方要求保留，否则删除被截断的下载文件
+	if errors.Is(err, ErrBodyTooLarge) && !req.KeepTruncatedBody {
+		_ = os.Remove(req.OutputFile)
+	}
+	return n, err
+}
@@ -0,0 +1,24 @@
+package core
+
+import "github.com/GoEnthusiast/httpreq/breaker"
+
+// allowCircuit asks the circuit breaker whether an attempt to host may be sent, done records the attempt's outcome
+// allowCircuit 询问熔断器是否可以向 host 发送一次尝试，done 记录该尝试的结果
+func (h *RequestHandler) allowCircuit(host string) (done func(statusCode int, err error), err error) {
+	h.mu.RLock()
+	b := h.breaker
+	h.mu.RUnlock()
+	if b == nil {
+		return func(int, error) {}, nil
+	}
+	return b.Allow(host)
+}
+
+// SetCircuitBreaker sets the per-host circuit breaker every attempt goes through, nil disables it
+// SetCircuitBreaker 设置每次尝试都要经过的按主机熔断器，nil 表示禁用
+func (h *RequestHandler) SetCircuitBreaker(b *breaker.Breaker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.breaker = b
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/cache"
+	"github.com/GoEnthusiast/httpreq/types/request"
+	"github.com/GoEnthusiast/httpreq/types/response"
+)
+
+// cacheLookup is the HTTP cache state of one request
+// cacheLookup 是单个请求的 HTTP 缓存状态
+type cacheLookup struct {
+	cache  *cache.Cache // Cache in use / 使用的缓存
+	header http.Header  // Request headers the cache decisions are based on / 缓存决策所依据的请求头
+	entry  *cache.Entry // Stored response, nil when none matched / 已存储的响应，没有匹配时为 nil
+	fresh  bool         // Whether entry is served without contacting the server / entry 是否不联系服务器直接提供
+}
+
+// lookupCache looks req up in the handler's cache and adds the validators of a stale entry to out.
+// It returns nil when no cache is set, the body is streamed or downloaded, or an authentication provider or signer
+// applies, those requests bypass the cache. Their credentials are only added per attempt, after the cache decisions,
+// so a response fetched with them must not be served to a request with different credentials.
+// lookupCache 在处理器的缓存中查找 req，并将过期条目的校验请求头添加到 out。
+// 未设置缓存、响应体以流式返回或下载、或使用认证提供者或签名器时返回 nil，这些请求不经过缓存。
+// 它们的认证信息在缓存决策之后才在每次尝试中添加，使用这些认证信息获取的响应不能提供给认证信息不同的请求。
+func (h *RequestHandler) lookupCache(req *request.Request, out *outgoing) *cacheLookup {
+	h.mu.RLock()
+	c := h.httpCache
+	h.mu.RUnlock()
+	if c == nil || req.StreamBody || hasBodySink(req) || h.requestAuth(req) != nil || h.requestSigner(req) != nil {
+		return nil
+	}
+
+	l := &cacheLookup{cache: c, header: h.requestHeader(req.Header)}
+	l.entry, l.fresh = c.Lookup(string(req.Method), out.url, l.header, time.Now())
+	if l.entry != nil && !l.fresh {
+		out.header = c.Validators(l.entry)
+	}
+	return l
+}
+
+// response returns the response answered by the cache alone, nil when the request has to be sent
+// response 返回仅由缓存回答的响应，请求需要发送时返回 nil
+func (l *cacheLookup) response(req *request.Request) *response.Response {
+	if l == nil {
+		return nil
+	}
+	now := time.Now()
+	resp := &response.Response{
+		Request:   req,
+		StartTime: now,
+		EndTime:   now,
+	}
+	switch {
+	case l.fresh:
+		fillFromEntry(resp, l.entry, now)
+		resp.CacheStatus = cache.StatusHit
+	case string(req.Method) == http.MethodGet && cache.OnlyIfCached(l.header):
+		// Nothing usable is stored and the request must not reach the server (RFC 7234 section 5.2.1.7)
+		// 没有可用的已存储响应，且请求不能发送到服务器（RFC 7234 第 5.2.1.7 节）
+		resp.ResponseStatusCode = http.StatusGatewayTimeout
+		resp.Header = make(http.Header)
+		resp.CacheStatus = cache.StatusMiss
+	default:
+		return nil
+	}
+	return resp
+}
+
+// update stores the response of a sent GET request, or serves the stored response when the server confirmed it
+// with 304 Not Modified. Successful requests with other methods invalidate the stored response of the URL.
+// update 存储已发送 GET 请求的响应，或在服务器以 304 Not Modified 确认时提供已存储的响应。
+// 其他方法的成功请求会使该地址的已存储响应失效。
+func (l *cacheLookup) update(req *request.Request, out *outgoing, resp *response.Response) {
+	if l == nil {
+		return
+	}
+	method := string(req.Method)
+	if method != http.MethodGet {
+		if resp.Error == nil {
+			l.cache.Invalidate(method, out.url, resp.ResponseStatusCode)
+		}
+		return
+	}
+
+	resp.CacheStatus = cache.StatusMiss
+	if resp.Error != nil || len(resp.Redirects) > 0 || len(resp.Attempts) == 0 {
+		return
+	}
+	requestTime, responseTime := resp.Attempts[len(resp.Attempts)-1].StartTime, resp.EndTime
+	if l.entry != nil && out.header != nil && resp.ResponseStatusCode == http.StatusNotModified {
+		entry := l.cache.Revalidate(l.entry, resp.Header, requestTime, responseTime)
+		fillFromEntry(resp, entry, time.Now())
+		resp.CacheStatus = cache.StatusRevalidated
+		return
+	}
+	l.cache.Put(method, l.header, &cache.Entry{
+		URL:          out.url,
+		StatusCode:   resp.ResponseStatusCode,
+		Proto:        resp.Proto,
+		Header:       resp.Header.Clone(),
+		Body:         bytes.Clone(resp.ResponseBody),
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+	})
+}
+
+// fillFromEntry sets the status, headers and body of resp from a stored response, adding its Age header
+// fillFromEntry 使用已存储的响应设置 resp 的状态、响应头和响应体，并添加 Age 响应头
+func fillFromEntry(resp *response.Response, entry *cache.Entry, now time.Time) {
+	resp.ResponseStatusCode = entry.StatusCode
+	resp.Header = entry.Header.Clone()
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	resp.Header.Set("Age", strconv.FormatInt(int64(entry.Age(now)/time.Second), 10))
+	resp.ResponseBody = bytes.Clone(entry.Body)
+	resp.BodySize = int64(len(entry.Body))
+	resp.ContentLength = resp.BodySize
+	resp.Proto = entry.Proto
+	resp.FinalURL = entry.URL
+}
+
+// SetCache sets the HTTP response cache used by GET requests, nil disables caching
+// SetCache 设置 GET 请求使用的 HTTP 响应缓存，nil 表示禁用缓存
+func (h *RequestHandler) SetCache(c *cache.Cache) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.httpCache = c
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// handlerJar is the jar of the shared client, delegating to the jar currently set on the handler
+// handlerJar 是共享客户端的 Cookie 容器，委托给处理器当前设置的容器
+type handlerJar struct {
+	h *RequestHandler // Owning handler / 所属处理器
+}
+
+// SetCookies stores cookies in the handler's jar, if any
+// SetCookies 将 Cookie 存入处理器的容器（如果已设置）
+func (j handlerJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if jar := j.h.cookieJar(); jar != nil {
+		jar.SetCookies(u, cookies)
+	}
+}
+
+// Cookies returns the cookies of the handler's jar for u, if any
+// Cookies 返回处理器容器中 u 对应的 Cookie（如果已设置）
+func (j handlerJar) Cookies(u *url.URL) []*http.Cookie {
+	if jar := j.h.cookieJar(); jar != nil {
+		return jar.Cookies(u)
+	}
+	return nil
+}
+
+// cookieJar returns the handler's cookie jar
+// cookieJar 返回处理器的 Cookie 容器
+func (h *RequestHandler) cookieJar() http.CookieJar {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.jar
+}
+
+// SetCookieJar sets the cookie jar shared by all requests, nil disables cookie handling
+// SetCookieJar 设置所有请求共享的 Cookie 容器，nil 表示不处理 Cookie
+func (h *RequestHandler) SetCookieJar(jar http.CookieJar) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.jar = jar
+}
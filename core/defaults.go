@@ -0,0 +1,135 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Option configures a request handler when it is created
+// Option 在创建请求处理器时对其进行配置
+type Option func(h *RequestHandler)
+
+// WithBaseURL sets the base URL relative request URLs are appended to, an invalid base URL fails every relative request
+// WithBaseURL 设置相对请求地址拼接的基础地址，无效的基础地址会使所有相对请求失败
+func WithBaseURL(baseURL string) Option {
+	return func(h *RequestHandler) {
+		h.baseURL = baseURL
+	}
+}
+
+// WithHeader adds a default header sent with every request
+// WithHeader 添加每个请求都发送的默认请求头
+func WithHeader(key, value string) Option {
+	return func(h *RequestHandler) {
+		h.header.Add(key, value)
+	}
+}
+
+// SetBaseURL sets the base URL relative request URLs are appended to, an empty string removes it
+// SetBaseURL 设置相对请求地址拼接的基础地址，空字符串表示移除
+func (h *RequestHandler) SetBaseURL(baseURL string) error {
+	if baseURL != "" {
+		if _, err := parseBaseURL(baseURL); err != nil {
+			return err
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.baseURL = baseURL
+	return nil
+}
+
+// SetHeader sets a default header sent with every request, request headers with the same key take precedence
+// SetHeader 设置每个请求都发送的默认请求头，请求中相同的请求头优先
+func (h *RequestHandler) SetHeader(key, value string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.header.Set(key, value)
+}
+
+// DelHeader removes a default header
+// DelHeader 删除一个默认请求头
+func (h *RequestHandler) DelHeader(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.header.Del(key)
+}
+
+// requestHeader returns a new header holding the default headers overridden by the request headers
+// requestHeader 返回一个新的请求头，包含默认请求头并由请求自身的请求头覆盖
+func (h *RequestHandler) requestHeader(header http.Header) http.Header {
+	h.mu.RLock()
+	merged := h.header.Clone()
+	h.mu.RUnlock()
+
+	for key, values := range header {
+		merged[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+	return merged
+}
+
+// resolveURL appends a relative rawURL to the base URL, absolute URLs are returned unchanged
+// resolveURL 将相对地址 rawURL 拼接到基础地址之后，绝对地址原样返回
+func (h *RequestHandler) resolveURL(rawURL string) (string, error) {
+	h.mu.RLock()
+	baseURL := h.baseURL
+	h.mu.RUnlock()
+
+	if baseURL == "" || !isRelativeURL(rawURL) {
+		return rawURL, nil
+	}
+	return joinURL(baseURL, rawURL)
+}
+
+// isRelativeURL reports whether rawURL has neither a scheme nor a host
+// isRelativeURL 报告 rawURL 是否既没有协议也没有主机
+func isRelativeURL(rawURL string) bool {
+	ref, err := url.Parse(rawURL)
+	return err == nil && !ref.IsAbs() && ref.Host == ""
+}
+
+// joinURL appends the relative rawURL to baseURL
+// joinURL 将相对地址 rawURL 拼接到 baseURL 之后
+func joinURL(baseURL, rawURL string) (string, error) {
+	base, err := parseBaseURL(baseURL)
+	if err != nil {
+		return "", err
+	}
+	base.RawQuery, base.Fragment = "", ""
+	if rawURL == "" || strings.HasPrefix(rawURL, "?") || strings.HasPrefix(rawURL, "#") {
+		return base.String() + rawURL, nil
+	}
+	return strings.TrimSuffix(base.String(), "/") + "/" + strings.TrimPrefix(rawURL, "/"), nil
+}
+
+// parseBaseURL parses an absolute base URL
+// parseBaseURL 解析绝对基础地址
+func parseBaseURL(baseURL string) (*url.URL, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse base url error: %w", err)
+	}
+	if !base.IsAbs() || base.Host == "" {
+		return nil, fmt.Errorf("base url must be absolute: %s", baseURL)
+	}
+	return base, nil
+}
+
+// urlHost returns the host of rawURL, rejecting schemes other than http and https
+// urlHost 返回 rawURL 的主机，拒绝 http 和 https 以外的协议
+func urlHost(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported protocol scheme %q", u.Scheme)
+	}
+	return u.Host, nil
+}
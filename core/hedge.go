@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/hedge"
+	"github.com/GoEnthusiast/httpreq/types/request"
+	"github.com/GoEnthusiast/httpreq/types/response"
+)
+
+// hedgeResult is the outcome of one copy of a hedged attempt
+// hedgeResult 是对冲尝试中一个副本的结果
+type hedgeResult struct {
+	copy int                // Copy number, 0 for the original / 副本序号，原始请求为 0
+	resp *response.Response // Response of the copy / 副本的响应
+	sent bool               // Whether retrying can help / 重试是否有意义
+}
+
+// doHedged sends an attempt of req and, following the hedging policy, extra copies when no reply arrives in time.
+// Every extra copy waits for the rate limiters. The first successful copy wins and the others are canceled; when every
+// copy in flight failed the last failure is returned to the retry policy. Without a policy, for non-idempotent methods
+// and for requests whose body is streamed or written to BodyWriter or OutputFile, which copies would write at once,
+// it is doAttempt.
+// doHedged 发送 req 的一次尝试，并按照对冲策略在未及时收到回复时发送额外副本，每个额外副本都要等待限流器。
+// 第一个成功的副本胜出，其他副本被取消；所有进行中的副本都失败时将最后的失败交给重试策略处理。
+// 没有策略、方法不幂等，以及响应体以流式返回或写入 BodyWriter、OutputFile（多个副本会同时写入）时等同于 doAttempt。
+func (h *RequestHandler) doHedged(ctx, reqCtx context.Context, req *request.Request, out *outgoing) (*response.Response, bool) {
+	policy := h.requestHedgePolicy(req)
+	if policy == nil || !hedge.Hedgeable(string(req.Method)) || req.StreamBody || hasBodySink(req) {
+		return h.doAttempt(ctx, reqCtx, req, out)
+	}
+
+	maxCopies := policy.MaxCopies() + 1
+	results := make(chan hedgeResult, maxCopies)
+	cancels := make([]context.CancelFunc, 0, maxCopies)
+	launch := func() {
+		copyCtx, cancel := context.WithCancel(reqCtx)
+		n := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			if n > 0 {
+				if _, err := h.waitRateLimit(ctx, copyCtx, req, out.host); err != nil {
+					resp := &response.Response{Request: req, StartTime: time.Now(), Error: err}
+					results <- hedgeResult{copy: n, resp: resp}
+					return
+				}
+			}
+			resp, sent := h.doAttempt(ctx, copyCtx, req, out)
+			results <- hedgeResult{copy: n, resp: resp, sent: sent}
+		}()
+	}
+	canLaunch := func() bool {
+		return len(cancels) < maxCopies && ctx.Err() == nil
+	}
+
+	// Latency is observed from the launch of the original, a late copy winning does not make the request faster
+	// 从原始请求发出时开始计算耗时，较晚发出的副本胜出并不会使请求更快
+	start := time.Now()
+	launch()
+	timer := time.NewTimer(policy.HedgeDelay())
+	defer timer.Stop()
+	var (
+		pending = 1
+		last    *hedgeResult
+	)
+	for {
+		select {
+		case <-timer.C:
+			if canLaunch() {
+				launch()
+				pending++
+				timer.Reset(policy.HedgeDelay())
+			}
+			continue
+		case result := <-results:
+			pending--
+			if hedgeSucceeded(result.resp) || pending == 0 {
+				h.finishHedge(policy, start, result, cancels, results, pending, last)
+				return result.resp, result.sent
+			}
+
+			// Keep the failure in case no copy in flight succeeds
+			// 保留失败结果以防进行中的副本都没有成功
+			if last != nil {
+				releaseHedge(*last, cancels)
+			}
+			last = &result
+		}
+	}
+}
+
+// finishHedge reports the winning copy on its response, observes the latency since start, the launch of the original,
+// cancels the other copies and releases their responses
+// finishHedge 在胜出副本的响应中记录结果，记录从 start（原始请求发出时）开始的耗时，取消其他副本并释放它们的响应
+func (h *RequestHandler) finishHedge(policy *hedge.Policy, start time.Time, winner hedgeResult, cancels []context.CancelFunc,
+	results <-chan hedgeResult, pending int, last *hedgeResult) {
+	winner.resp.Hedges = len(cancels) - 1
+	winner.resp.HedgeWinner = winner.copy
+	if hedgeSucceeded(winner.resp) {
+		policy.Observe(time.Since(start))
+	}
+
+	// A streamed body keeps the winner's context alive until it is consumed
+	// 流式响应体在读取完之前保持胜出副本的上下文有效
+	if body, ok := winner.resp.BodyReader.(*bodyReader); ok {
+		body.cleanups = append(body.cleanups, cancels[winner.copy])
+	} else {
+		cancels[winner.copy]()
+	}
+	if last != nil {
+		releaseHedge(*last, cancels)
+	}
+	for i, cancel := range cancels {
+		if i != winner.copy {
+			cancel()
+		}
+	}
+	go func() {
+		for ; pending > 0; pending-- {
+			releaseHedge(<-results, cancels)
+		}
+	}()
+}
+
+// releaseHedge closes the streamed body of a losing copy and releases its context
+// releaseHedge 关闭落败副本的流式响应体并释放其上下文
+func releaseHedge(result hedgeResult, cancels []context.CancelFunc) {
+	if result.resp.BodyReader != nil {
+		_ = result.resp.BodyReader.Close()
+	}
+	cancels[result.copy]()
+}
+
+// hedgeSucceeded reports whether a copy got a reply that is not a server error
+// hedgeSucceeded 报告副本是否收到了非服务器错误的回复
+func hedgeSucceeded(resp *response.Response) bool {
+	return resp.Error == nil && resp.ResponseStatusCode < http.StatusInternalServerError
+}
+
+// requestHedgePolicy returns the hedging policy for req, falling back to the handler default
+// requestHedgePolicy 返回 req 的对冲策略，未设置时使用处理器默认值
+func (h *RequestHandler) requestHedgePolicy(req *request.Request) *hedge.Policy {
+	if req.Hedge != nil {
+		return req.Hedge
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.hedgePolicy
+}
+
+// SetHedgePolicy sets the default hedging policy used when a request does not set its own, nil disables hedging
+// SetHedgePolicy 设置请求未单独指定时使用的默认对冲策略，nil 表示禁用对冲
+func (h *RequestHandler) SetHedgePolicy(policy *hedge.Policy) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.hedgePolicy = policy
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/ratelimit"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// waitRateLimit waits for every rate limiter in turn before the attempt of req to host bound to reqCtx and returns the
+// total time waited. The wait shares the total timeout of the attempt, whose expiry is reported as a request timeout error.
+// waitRateLimit 在 req 绑定到 reqCtx 的、发往 host 的尝试之前依次等待每个限流器，并返回总等待时间。
+// 等待与该尝试共享总超时，超时到期时报告请求超时错误。
+func (h *RequestHandler) waitRateLimit(ctx, reqCtx context.Context, req *request.Request, host string) (time.Duration, error) {
+	h.mu.RLock()
+	limiters := h.limiters
+	h.mu.RUnlock()
+	if len(limiters) == 0 {
+		return 0, nil
+	}
+
+	waitCtx := reqCtx
+	if timeout, _ := h.requestTimeouts(req); timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithDeadlineCause(reqCtx, attemptDeadline(reqCtx, timeout), timeoutError("total", timeout))
+		defer cancel()
+	}
+	var waited time.Duration
+	for _, limiter := range limiters {
+		wait, err := limiter.Wait(waitCtx, host, req)
+		waited += wait
+		if err != nil {
+			if ctxE := contextError(ctx, waitCtx); ctxE != nil {
+				return waited, ctxE
+			}
+			return waited, canceledError(err)
+		}
+	}
+	return waited, nil
+}
+
+// SetRateLimit sets the rate limiters every attempt waits for, replacing the previous ones; no limiters removes rate limiting
+// SetRateLimit 设置每次尝试都要等待的限流器，替换之前的设置；不传限流器表示取消限流
+func (h *RequestHandler) SetRateLimit(limiters ...*ratelimit.Limiter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.limiters = append([]*ratelimit.Limiter(nil), limiters...)
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/GoEnthusiast/httpreq/redirect"
+	"github.com/GoEnthusiast/httpreq/types/response"
+)
+
+// redirectStateKey is the context key of the redirect state of an attempt
+// redirectStateKey 是尝试重定向状态的上下文键
+type redirectStateKey struct{}
+
+// redirectState carries the redirect policy of an attempt and records the redirects it followed
+// redirectState 携带尝试的重定向策略，并记录已跟随的重定向
+type redirectState struct {
+	policy *redirect.Policy    // Redirect policy of the request / 请求的重定向策略
+	hops   []response.Redirect // Redirects followed so far / 已跟随的重定向
+}
+
+// withRedirectState binds a redirect state to ctx
+// withRedirectState 将重定向状态绑定到 ctx
+func withRedirectState(ctx context.Context, state *redirectState) context.Context {
+	return context.WithValue(ctx, redirectStateKey{}, state)
+}
+
+// checkRedirect is the CheckRedirect function of the shared client, applying the policy bound to the request context
+// checkRedirect 是共享客户端的 CheckRedirect 函数，应用绑定在请求上下文中的策略
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	state, _ := req.Context().Value(redirectStateKey{}).(*redirectState)
+	if state == nil {
+		return (*redirect.Policy)(nil).Check(req, via)
+	}
+	if err := state.policy.Check(req, via); err != nil {
+		return err
+	}
+	if req.Response != nil {
+		state.hops = append(state.hops, response.Redirect{
+			URL:        req.Response.Request.URL.String(),
+			StatusCode: req.Response.StatusCode,
+			Header:     req.Response.Header,
+			Location:   req.URL.String(),
+		})
+	}
+	return nil
+}
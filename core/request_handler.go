@@ -3,111 +3,489 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/GoEnthusiast/httpreq/auth"
+	"github.com/GoEnthusiast/httpreq/balancer"
+	"github.com/GoEnthusiast/httpreq/breaker"
 	"github.com/GoEnthusiast/httpreq/builder"
+	"github.com/GoEnthusiast/httpreq/cache"
+	"github.com/GoEnthusiast/httpreq/hedge"
+	"github.com/GoEnthusiast/httpreq/interceptor"
+	"github.com/GoEnthusiast/httpreq/ratelimit"
+	"github.com/GoEnthusiast/httpreq/redirect"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/retry"
+	"github.com/GoEnthusiast/httpreq/signer"
 	"github.com/GoEnthusiast/httpreq/transportsetting"
 	"github.com/GoEnthusiast/httpreq/types/request"
 	"github.com/GoEnthusiast/httpreq/types/response"
 )
 
+var (
+	// ErrRequestCanceled is reported on the response when the request context is canceled or its deadline expires.
+	// It is an alias of reqerr.ErrCanceled.
+	// ErrRequestCanceled 在请求上下文被取消或截止时间到期时报告到响应中，是 reqerr.ErrCanceled 的别名
+	ErrRequestCanceled = reqerr.ErrCanceled
+
+	// ErrBodyTooLarge is reported on the response when the response body exceeds the maximum body size.
+	// It is an alias of reqerr.ErrTooLarge.
+	// ErrBodyTooLarge 在响应体超过最大大小时报告到响应中，是 reqerr.ErrTooLarge 的别名
+	ErrBodyTooLarge = reqerr.ErrTooLarge
+
+	// ErrRequestTimeout is reported on the response when the request's own total or header timeout expires.
+	// It is an alias of reqerr.ErrTimeout.
+	// ErrRequestTimeout 在请求自身的总超时或响应头超时到期时报告到响应中，是 reqerr.ErrTimeout 的别名
+	ErrRequestTimeout = reqerr.ErrTimeout
+)
+
 // RequestHandler is the core request processor that handles HTTP requests
 // RequestHandler 是处理 HTTP 请求的核心请求处理器
 type RequestHandler struct {
-	*transportsetting.TransportSetting              // Transport configuration / 传输层配置
-	client                             *http.Client // HTTP client instance / HTTP 客户端实例
+	*transportsetting.TransportSetting                      // Transport configuration / 传输层配置
+	client                             *http.Client         // HTTP client instance / HTTP 客户端实例
+	timeout                            time.Duration        // Default total request timeout / 默认请求总超时时间
+	headerTimeout                      time.Duration        // Default response header timeout / 默认响应头超时时间
+	retryPolicy                        *retry.Policy        // Default retry policy / 默认重试策略
+	redirectPolicy                     *redirect.Policy     // Default redirect policy / 默认重定向策略
+	hedgePolicy                        *hedge.Policy        // Default hedging policy / 默认对冲策略
+	jar                                http.CookieJar       // Cookie jar, nil disables cookies / Cookie 容器，nil 表示不处理 Cookie
+	baseURL                            string               // Base URL for relative request URLs / 相对请求地址的基础地址
+	header                             http.Header          // Default request headers / 默认请求头
+	auth                               auth.Provider        // Default authentication provider / 默认认证提供者
+	signer                             signer.Signer        // Default request signer / 默认请求签名器
+	limiters                           []*ratelimit.Limiter // Rate limiters waited for before every attempt / 每次尝试前等待的限流器
+	balancer                           *balancer.Balancer   // Load balancer for relative URLs, nil disables it / 相对地址的负载均衡器，nil 表示禁用
+	breaker                            *breaker.Breaker     // Per-host circuit breaker, nil disables it / 按主机划分的熔断器，nil 表示禁用
+	httpCache                          *cache.Cache         // HTTP response cache, nil disables caching / HTTP 响应缓存，nil 表示禁用缓存
+	maxBodySize                        int64                // Default maximum response body size, 0 means unlimited / 默认最大响应体大小，0 表示不限制
+	interceptors                       *interceptor.Chain   // Middlewares and hooks / 中间件和钩子
+	mu                                 sync.RWMutex         // Mutex for handler settings / 处理器设置的读写锁
 }
 
-// NewRequestHandler creates a new request handler with optional HTTP/2 support
-// NewRequestHandler 创建一个新的请求处理器，支持可选的 HTTP/2
-func NewRequestHandler(enableHttp2 bool) *RequestHandler {
+// NewRequestHandler creates a new request handler with optional HTTP/2 support and options
+// NewRequestHandler 创建一个新的请求处理器，支持可选的 HTTP/2 和配置项
+func NewRequestHandler(enableHttp2 bool, opts ...Option) *RequestHandler {
 	transportSetting := transportsetting.NewTransportSetting(enableHttp2)
-	return &RequestHandler{
+	h := &RequestHandler{
 		TransportSetting: transportSetting,
-		client: &http.Client{
-			Transport: transportSetting.GetTransport(),
-		},
+		interceptors:     interceptor.NewChain(),
+		header:           make(http.Header),
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.client = &http.Client{
+		Transport:     transportSetting,
+		CheckRedirect: checkRedirect,
+		Jar:           handlerJar{h: h},
+	}
+	return h
 }
 
 // ProcessRequest processes a single HTTP request and returns the response
 // ProcessRequest 处理单个 HTTP 请求并返回响应
 func (h *RequestHandler) ProcessRequest(req *request.Request) *response.Response {
+	return h.ProcessRequestContext(context.Background(), req)
+}
+
+// ProcessRequestContext processes a single HTTP request bound to ctx and returns the response.
+// The request passes through the registered middlewares, and failed attempts are retried
+// according to the request's or the handler's retry policy.
+// ProcessRequestContext 处理绑定到 ctx 的单个 HTTP 请求并返回响应。
+// 请求会经过已注册的中间件，失败的尝试会按照请求或处理器的重试策略进行重试。
+func (h *RequestHandler) ProcessRequestContext(ctx context.Context, req *request.Request) *response.Response {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	startTime := time.Now()
-	resp := &response.Response{
-		Request:   req,
-		StartTime: startTime,
+	resp := h.interceptors.Then(h.processRequest)(ctx, req)
+	if resp == nil {
+		resp = &response.Response{
+			Request: req,
+			Error:   reqerr.New(reqerr.KindInvalidRequest, "middleware returned no response", nil),
+		}
+	}
+	resp.StartTime = startTime
+	resp.EndTime = time.Now()
+	resp.Duration = resp.EndTime.Sub(startTime).Seconds()
+	return resp
+}
+
+// processRequest sends req with retries and runs the after-response hooks on the final response
+// processRequest 发送 req（包括重试），并对最终响应执行响应后钩子
+func (h *RequestHandler) processRequest(ctx context.Context, req *request.Request) (resp *response.Response) {
+	resp = &response.Response{
+		Request: req,
 	}
 	defer func() {
-		resp.EndTime = time.Now()
-		resp.Duration = resp.EndTime.Sub(startTime).Seconds()
+		h.interceptors.AfterResponse(resp)
 	}()
 
-	// Build request body based on content type
-	// 根据内容类型构建请求体
+	// Fail fast if the context is already done
+	// 如果上下文已结束则快速失败
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		resp.Error = canceledError(ctxErr)
+		return resp
+	}
+
+	// Resolve the URL against the base URL, expand path parameters and merge query parameters.
+	// Relative URLs stay relative when a load balancer picks the endpoint of each attempt,
+	// unix:// URLs are sent as HTTP over the socket they name.
+	// 基于基础地址解析请求地址，展开路径参数并合并查询参数。负载均衡器为每次尝试选择端点时相对地址保持不变，
+	// unix:// 地址通过其指定的套接字以 HTTP 发送。
+	out := &outgoing{balancer: h.requestBalancer(req.URL)}
+	socketPath, rawURL, isUnix := transportsetting.ParseUnixURL(req.URL)
+	var urlE error
+	if !isUnix {
+		rawURL = req.URL
+		if out.balancer == nil {
+			rawURL, urlE = h.resolveURL(req.URL)
+		}
+	}
+	if urlE == nil {
+		out.url, urlE = builder.BuildRequestURL(rawURL, req.PathParams, req.Query)
+	}
+	if urlE == nil && out.balancer == nil {
+		out.host, urlE = urlHost(out.url)
+	}
+	if urlE != nil {
+		resp.Error = reqerr.New(reqerr.KindInvalidRequest, "build request url error", urlE)
+		return resp
+	}
+
+	// Build request body based on content type, buffered so every attempt can replay it
+	// 根据内容类型构建请求体，并缓存下来以便每次尝试都能重新发送
 	body, contentType, bodyE := builder.BuildRequestBody(req.ContentType, req.Body)
 	if bodyE != nil {
-		resp.Error = fmt.Errorf("build request body error: %s", bodyE.Error())
+		resp.Error = reqerr.New(reqerr.KindBodyBuild, "build request body error", bodyE)
 		return resp
 	}
+	out.contentType = contentType
+	if body != nil {
+		if out.body, bodyE = io.ReadAll(body); bodyE != nil {
+			resp.Error = reqerr.New(reqerr.KindBodyBuild, "build request body error", bodyE)
+			return resp
+		}
+	}
 
-	// Create HTTP request
-	// 创建 HTTP 请求
-	httpReq, err := http.NewRequest(string(req.Method), req.URL, body)
-	if err != nil {
-		resp.Error = fmt.Errorf("new http request error: %s", err.Error())
+	// Bind the per-request proxy to the request context instead of mutating the shared transport
+	// 将单请求代理绑定到请求上下文，而不是修改共享的传输层
+	reqCtx, proxyE := transportsetting.WithProxy(ctx, req.Proxy)
+	if proxyE != nil {
+		resp.Error = reqerr.New(reqerr.KindProxy, "set proxy error", proxyE)
 		return resp
 	}
+	reqCtx = transportsetting.WithResolve(reqCtx, req.Resolve)
+	reqCtx = request.NewContext(reqCtx, req)
+	reqCtx = transportsetting.WithUnixSocket(reqCtx, socketPath)
 
-	// Set request headers
-	// 设置请求头
-	if req.Header != nil {
-		httpReq.Header = req.Header
-	} else {
-		httpReq.Header = make(http.Header)
+	// Serve fresh responses from the HTTP cache, stale ones are sent with validators to be revalidated
+	// 由 HTTP 缓存直接提供新鲜的响应，过期的响应携带校验请求头发送以重新验证
+	lookup := h.lookupCache(req, out)
+	if cached := lookup.response(req); cached != nil {
+		resp = cached
+		return resp
 	}
 
-	// Set content-type header
-	// 设置 content-type 头部
-	if contentType != "" {
-		httpReq.Header.Set("Content-Type", contentType)
+	resp = h.sendWithRetry(ctx, reqCtx, req, out)
+	lookup.update(req, out, resp)
+	return resp
+}
+
+// sendWithRetry sends attempts of req until one succeeds or the retry policy gives up, and returns the last response
+// sendWithRetry 发送 req 的尝试，直到成功或重试策略放弃，并返回最后一次的响应
+func (h *RequestHandler) sendWithRetry(ctx, reqCtx context.Context, req *request.Request, out *outgoing) *response.Response {
+	resp := &response.Response{
+		Request: req,
 	}
+	policy := h.requestRetryPolicy(req)
+	var (
+		attempts []response.Attempt
+		waited   time.Duration
+	)
+	for attempt := 1; ; attempt++ {
+		attemptCtx := withAttemptStart(reqCtx)
 
-	// Configure proxy settings
-	// 配置代理设置
-	if proxyE := h.TransportSetting.SetProxy(req.Proxy); proxyE != nil {
-		resp.Error = fmt.Errorf("set proxy error: %s", proxyE.Error())
-		return resp
+		// Send the attempt to the endpoint chosen by the load balancer
+		// 将尝试发送到负载均衡器选择的端点
+		target, release, targetE := pickEndpoint(req, out)
+		if targetE != nil {
+			resp.Error = reqerr.New(reqerr.KindInvalidRequest, "build request url error", targetE)
+			return resp
+		}
+
+		// Wait for the rate limiters before taking a circuit breaker slot, giving up if the context or the attempt's
+		// timeout ends first; nothing was sent then, so the endpoint does not count the outcome
+		// 在占用熔断器名额之前等待限流器，如果上下文或尝试超时先结束则放弃；此时没有发送任何请求，因此端点不统计该结果
+		wait, waitE := h.waitRateLimit(ctx, attemptCtx, req, target.host)
+		waited += wait
+		if waitE != nil {
+			resp.Error = waitE
+			resp.RateLimitWait = waited
+			resp.Endpoint = target.endpoint
+			release(0, reqerr.ErrCanceled)
+			return resp
+		}
+
+		// Fail fast while the circuit breaker of the host is open
+		// 主机的熔断器打开时快速失败
+		done, breakerE := h.allowCircuit(target.host)
+		if breakerE != nil {
+			resp.Error = breakerE
+			resp.RateLimitWait = waited
+			resp.Endpoint = target.endpoint
+			release(0, reqerr.ErrCanceled)
+			return resp
+		}
+
+		var sent bool
+		resp, sent = h.doHedged(ctx, attemptCtx, req, target)
+		// A streamed body is still being read, so the attempt ends when its headers are returned
+		// 流式响应体仍在读取中，因此尝试在返回响应头时结束
+		returned := time.Now()
+		resp.RateLimitWait = waited
+		resp.Endpoint = target.endpoint
+		done(resp.ResponseStatusCode, resp.Error)
+		release(resp.ResponseStatusCode, resp.Error)
+		attempts = append(attempts, response.Attempt{
+			Number:     attempt,
+			StatusCode: resp.ResponseStatusCode,
+			Error:      resp.Error,
+			StartTime:  resp.StartTime,
+			Duration:   returned.Sub(resp.StartTime),
+		})
+		resp.Attempts = attempts
+
+		if !sent || policy == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil ||
+			!policy.ShouldRetry(string(req.Method), resp.ResponseStatusCode, resp.Error) {
+			return resp
+		}
+		if resp.BodyReader != nil {
+			_ = resp.BodyReader.Close()
+		}
+
+		// Wait before the next attempt, giving up if the context ends first
+		// 在下一次尝试前等待，如果上下文先结束则放弃
+		if wait := policy.Delay(attempt, resp.Header); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				resp.Error = canceledError(ctx.Err())
+				return resp
+			}
+		}
 	}
+}
+
+// canceledError wraps a context error so that both ErrRequestCanceled and the context error match with errors.Is
+// canceledError 包装上下文错误，使 ErrRequestCanceled 与上下文错误均可通过 errors.Is 匹配
+func canceledError(ctxErr error) error {
+	return reqerr.New(reqerr.KindCanceled, "request canceled", ctxErr)
+}
 
-	h.client.Transport = h.TransportSetting.GetTransport()
-	// Set request timeout
-	// 设置请求超时时间
-	h.client.Timeout = req.Timeout
+// timeoutError builds the error reported when one of the request's own timeouts expires
+// timeoutError 构建请求自身某个超时到期时报告的错误
+func timeoutError(kind string, timeout time.Duration) error {
+	return reqerr.New(reqerr.KindTimeout, fmt.Sprintf("request timeout: %s timeout %s exceeded", kind, timeout), context.DeadlineExceeded)
+}
 
-	// Execute HTTP request
-	// 执行 HTTP 请求
-	httpResp, err := h.client.Do(httpReq)
-	if err != nil {
-		resp.Error = fmt.Errorf("do http request error: %s", err.Error())
-		return resp
+// contextError reports why the request context ended: a canceled parent or one of the request's own timeouts.
+// It returns nil when the context is still active.
+// contextError 报告请求上下文结束的原因：父上下文被取消或请求自身超时。上下文仍有效时返回 nil。
+func contextError(parent, reqCtx context.Context) error {
+	if ctxErr := parent.Err(); ctxErr != nil {
+		return canceledError(ctxErr)
 	}
-	defer httpResp.Body.Close()
+	if cause := context.Cause(reqCtx); errors.Is(cause, ErrRequestTimeout) {
+		return cause
+	}
+	return nil
+}
 
-	// Read response body
-	// 读取响应体
-	respBody, err := io.ReadAll(httpResp.Body)
-	if err != nil {
-		resp.Error = fmt.Errorf("read response body error: %s", err.Error())
-		return resp
+// requestTimeouts returns the total and header timeouts for req, falling back to the handler defaults
+// requestTimeouts 返回 req 的总超时和响应头超时，未设置时使用处理器默认值
+func (h *RequestHandler) requestTimeouts(req *request.Request) (time.Duration, time.Duration) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	timeout, headerTimeout := req.Timeout, req.HeaderTimeout
+	if timeout <= 0 {
+		timeout = h.timeout
 	}
+	if headerTimeout <= 0 {
+		headerTimeout = h.headerTimeout
+	}
+	return timeout, headerTimeout
+}
 
-	resp.ResponseStatusCode = httpResp.StatusCode
-	resp.ResponseBody = respBody
-	return resp
+// SetTimeout sets the default total timeout used when a request does not set its own
+// SetTimeout 设置请求未单独指定时使用的默认总超时时间
+func (h *RequestHandler) SetTimeout(timeout time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.timeout = timeout
+}
+
+// SetResponseHeaderTimeout sets the default response header timeout used when a request does not set its own
+// SetResponseHeaderTimeout 设置请求未单独指定时使用的默认响应头超时时间
+func (h *RequestHandler) SetResponseHeaderTimeout(headerTimeout time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.headerTimeout = headerTimeout
+}
+
+// requestRetryPolicy returns the retry policy for req, falling back to the handler default
+// requestRetryPolicy 返回 req 的重试策略，未设置时使用处理器默认值
+func (h *RequestHandler) requestRetryPolicy(req *request.Request) *retry.Policy {
+	if req.Retry != nil {
+		return req.Retry
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.retryPolicy
+}
+
+// requestRedirectPolicy returns the redirect policy for req, falling back to the handler default
+// requestRedirectPolicy 返回 req 的重定向策略，未设置时使用处理器默认值
+func (h *RequestHandler) requestRedirectPolicy(req *request.Request) *redirect.Policy {
+	if req.Redirect != nil {
+		return req.Redirect
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.redirectPolicy
+}
+
+// requestAuth returns the authentication provider for req, falling back to the handler default
+// requestAuth 返回 req 的认证提供者，未设置时使用处理器默认值
+func (h *RequestHandler) requestAuth(req *request.Request) auth.Provider {
+	if req.Auth != nil {
+		return req.Auth
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.auth
+}
+
+// requestSigner returns the signer for req, falling back to the handler default
+// requestSigner 返回 req 的签名器，未设置时使用处理器默认值
+func (h *RequestHandler) requestSigner(req *request.Request) signer.Signer {
+	if req.Signer != nil {
+		return req.Signer
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.signer
+}
+
+// requestMaxBodySize returns the maximum body size for req, 0 meaning unlimited
+// requestMaxBodySize 返回 req 的最大响应体大小，0 表示不限制
+func (h *RequestHandler) requestMaxBodySize(req *request.Request) int64 {
+	if req.MaxBodySize > 0 {
+		return req.MaxBodySize
+	}
+	if req.MaxBodySize < 0 {
+		return 0
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.maxBodySize
+}
+
+// SetMaxBodySize sets the default maximum response body size in bytes, 0 means unlimited
+// SetMaxBodySize 设置默认的最大响应体字节数，0 表示不限制
+func (h *RequestHandler) SetMaxBodySize(maxBodySize int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.maxBodySize = maxBodySize
+}
+
+// SetRetryPolicy sets the default retry policy used when a request does not set its own, nil disables retries
+// SetRetryPolicy 设置请求未单独指定时使用的默认重试策略，nil 表示禁用重试
+func (h *RequestHandler) SetRetryPolicy(policy *retry.Policy) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.retryPolicy = policy
+}
+
+// SetRedirectPolicy sets the default redirect policy used when a request does not set its own, nil follows up to 10 redirects
+// SetRedirectPolicy 设置请求未单独指定时使用的默认重定向策略，nil 表示最多跟随 10 次重定向
+func (h *RequestHandler) SetRedirectPolicy(policy *redirect.Policy) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.redirectPolicy = policy
+}
+
+// SetAuth sets the default authentication provider used when a request does not set its own, nil disables it
+// SetAuth 设置请求未单独指定时使用的默认认证提供者，nil 表示禁用
+func (h *RequestHandler) SetAuth(provider auth.Provider) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.auth = provider
+}
+
+// SetSigner sets the default request signer used when a request does not set its own, nil disables signing
+// SetSigner 设置请求未单独指定时使用的默认请求签名器，nil 表示不签名
+func (h *RequestHandler) SetSigner(s signer.Signer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.signer = s
+}
+
+// Use registers middlewares wrapping the full round trip, the first registered is outermost
+// Use 注册包装完整往返过程的中间件，先注册的在最外层
+func (h *RequestHandler) Use(middlewares ...interceptor.Middleware) {
+	h.interceptors.Use(middlewares...)
+}
+
+// OnBeforeRequest registers a hook called for every attempt before the HTTP request is sent
+// OnBeforeRequest 注册在每次尝试发送 HTTP 请求之前调用的钩子
+func (h *RequestHandler) OnBeforeRequest(fn interceptor.BeforeRequestFunc) {
+	h.interceptors.OnBeforeRequest(fn)
+}
+
+// OnAfterResponse registers a hook called when a response has been received successfully
+// OnAfterResponse 注册在成功收到响应后调用的钩子
+func (h *RequestHandler) OnAfterResponse(fn interceptor.AfterResponseFunc) {
+	h.interceptors.OnAfterResponse(fn)
+}
+
+// OnError registers a hook called when a request ends with an error
+// OnError 注册在请求以错误结束时调用的钩子
+func (h *RequestHandler) OnError(fn interceptor.ErrorFunc) {
+	h.interceptors.OnError(fn)
+}
+
+// SetConnectTimeout sets the default TCP connect timeout used when a request does not set its own
+// SetConnectTimeout 设置请求未单独指定时使用的默认 TCP 连接超时时间
+func (h *RequestHandler) SetConnectTimeout(connectTimeout time.Duration) {
+	h.TransportSetting.SetConnectTimeout(connectTimeout)
 }
 
 // SetTLS configures TLS settings with certificate files
@@ -169,3 +547,17 @@ func (h *RequestHandler) SetExpectContinueTimeout(expectContinueTimeout time.Dur
 func (h *RequestHandler) SetDisableKeepAlives(disableKeepAlives bool) {
 	h.TransportSetting.SetDisableKeepAlives(disableKeepAlives)
 }
+
+// SetMaxProxyTransports sets the maximum number of per-proxy transports kept in the pool. It is a soft cap: when every
+// pooled transport has a request in flight a new one is still created, and the pool shrinks back as requests finish.
+// SetMaxProxyTransports 设置池中保留的按代理划分的传输层最大数量。这是一个软上限：所有池化传输层都有进行中的请求时
+// 仍会创建新的传输层，请求结束后传输层池会缩减回上限。
+func (h *RequestHandler) SetMaxProxyTransports(maxProxyTransports int) {
+	h.TransportSetting.SetMaxProxyTransports(maxProxyTransports)
+}
+
+// SetProxyTransportIdleTimeout sets how long an unused per-proxy transport is kept before eviction
+// SetProxyTransportIdleTimeout 设置未使用的按代理划分的传输层在被淘汰前保留的时间
+func (h *RequestHandler) SetProxyTransportIdleTimeout(proxyTransportIdleTimeout time.Duration) {
+	h.TransportSetting.SetProxyTransportIdleTimeout(proxyTransportIdleTimeout)
+}
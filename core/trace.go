@@ -0,0 +1,101 @@
+package core
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/types/response"
+)
+
+// phaseTrace collects the phase timing of one attempt through net/http/httptrace
+// phaseTrace 通过 net/http/httptrace 收集单次尝试的各阶段耗时
+type phaseTrace struct {
+	start        time.Time       // Attempt start time / 尝试开始时间
+	dnsStart     time.Time       // DNS lookup start time / DNS 解析开始时间
+	connectStart time.Time       // TCP connect start time / TCP 连接开始时间
+	tlsStart     time.Time       // TLS handshake start time / TLS 握手开始时间
+	firstByte    time.Time       // Time the first response byte arrived / 收到第一个响应字节的时间
+	timing       response.Timing // Collected timing / 已收集的耗时
+	mu           sync.Mutex      // Callbacks may run on dialing goroutines / 回调可能在拨号协程中执行
+}
+
+// newPhaseTrace creates a phase trace for an attempt started at start
+// newPhaseTrace 为在 start 时开始的尝试创建阶段追踪
+func newPhaseTrace(start time.Time) *phaseTrace {
+	return &phaseTrace{start: start}
+}
+
+// clientTrace returns the httptrace hooks that feed the trace
+// clientTrace 返回向追踪写入数据的 httptrace 钩子
+func (p *phaseTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if !p.dnsStart.IsZero() {
+				p.timing.DNSLookup = time.Since(p.dnsStart)
+			}
+		},
+		ConnectStart: func(_, _ string) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			// Parallel dials (happy eyeballs) share the first start time
+			// 并行拨号（happy eyeballs）共用第一次开始时间
+			if p.connectStart.IsZero() {
+				p.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if err == nil && p.timing.TCPConnect == 0 {
+				p.timing.TCPConnect = time.Since(p.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if !p.tlsStart.IsZero() {
+				p.timing.TLSHandshake = time.Since(p.tlsStart)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.timing.ConnReused = info.Reused
+			if addr := info.Conn.RemoteAddr(); addr != nil {
+				p.timing.RemoteAddr = addr.String()
+			}
+		},
+		GotFirstResponseByte: func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.firstByte = time.Now()
+			p.timing.TimeToFirstByte = p.firstByte.Sub(p.start)
+		},
+	}
+}
+
+// snapshot returns the timing collected so far, with the body transfer measured up to end
+// snapshot 返回目前收集到的耗时，响应体传输时间计算到 end 为止
+func (p *phaseTrace) snapshot(end time.Time) response.Timing {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	timing := p.timing
+	if !p.firstByte.IsZero() && end.After(p.firstByte) {
+		timing.BodyTransfer = end.Sub(p.firstByte)
+	}
+	return timing
+}
@@ -2,6 +2,18 @@ module github.com/GoEnthusiast/httpreq
 
 go 1.23.5
 
-require golang.org/x/net v0.42.0
+require (
+	github.com/quic-go/quic-go v0.54.1
+	golang.org/x/net v0.42.0
+)
 
-require golang.org/x/text v0.27.0 // indirect
+require (
+	github.com/quic-go/qpack v0.5.1 // indirect
+	go.uber.org/mock v0.5.0 // indirect
+	golang.org/x/crypto v0.40.0 // indirect
+	golang.org/x/mod v0.25.0 // indirect
+	golang.org/x/sync v0.16.0 // indirect
+	golang.org/x/sys v0.34.0 // indirect
+	golang.org/x/text v0.27.0 // indirect
+	golang.org/x/tools v0.34.0 // indirect
+)
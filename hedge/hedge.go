@@ -0,0 +1,111 @@
+// Package hedge provides hedging policies that send extra copies of slow requests to cut tail latency
+// 包 hedge 提供对冲策略，为慢请求发送额外副本以降低尾延迟
+package hedge
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Defaults used when a Policy field is zero
+// Policy 字段为零时使用的默认值
+const (
+	DefaultMaxHedges  = 1   // Default number of extra copies / 默认额外副本数
+	DefaultMinSamples = 20  // Default number of samples before the percentile is used / 使用百分位数前默认需要的样本数
+	DefaultWindow     = 100 // Default number of recent latencies kept / 默认保留的最近耗时数量
+)
+
+// Policy describes when extra copies of a request are sent. Only idempotent methods are hedged.
+// The first successful copy wins and the others are canceled. A policy learns latencies, so share one
+// policy between requests to the same service.
+// Policy 描述何时发送请求的额外副本，只对幂等方法进行对冲。第一个成功的副本胜出，其他副本被取消。
+// 策略会学习耗时，因此请在发往同一服务的请求之间共享同一个策略。
+type Policy struct {
+	Delay      time.Duration // Wait before sending a copy, also the fallback while too few latencies are known / 发送副本前的等待时间，也是已知耗时不足时的后备值
+	Percentile float64       // When set (e.g. 0.95), wait for this percentile of recent latencies instead / 设置后（例如 0.95）改为等待最近耗时的该百分位数
+	MinSamples int           // Latencies needed before the percentile is used, DefaultMinSamples when zero / 使用百分位数前需要的耗时样本数，为零时使用 DefaultMinSamples
+	Window     int           // Number of recent latencies kept, DefaultWindow when zero / 保留的最近耗时数量，为零时使用 DefaultWindow
+	MaxHedges  int           // Maximum number of extra copies, DefaultMaxHedges when zero / 最大额外副本数，为零时使用 DefaultMaxHedges
+
+	latencies []time.Duration // Ring buffer of recent latencies / 最近耗时的环形缓冲区
+	next      int             // Next ring buffer slot / 环形缓冲区的下一个位置
+	mu        sync.Mutex      // Mutex for latencies / 耗时数据的互斥锁
+}
+
+// NewPolicy creates a policy sending one extra copy when no reply arrived within delay
+// NewPolicy 创建一个在 delay 内未收到回复时发送一个额外副本的策略
+func NewPolicy(delay time.Duration) *Policy {
+	return &Policy{Delay: delay}
+}
+
+// NewPercentilePolicy creates a policy sending one extra copy after the percentile of recent latencies,
+// waiting fallback until enough latencies are known
+// NewPercentilePolicy 创建一个在最近耗时的 percentile 百分位数后发送一个额外副本的策略，已知耗时不足时等待 fallback
+func NewPercentilePolicy(percentile float64, fallback time.Duration) *Policy {
+	return &Policy{Delay: fallback, Percentile: percentile}
+}
+
+// Hedgeable reports whether requests with method may be hedged, i.e. whether the method is idempotent
+// Hedgeable 报告 method 的请求是否可以对冲，即该方法是否幂等
+func Hedgeable(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// MaxCopies returns the maximum number of extra copies
+// MaxCopies 返回最大额外副本数
+func (p *Policy) MaxCopies() int {
+	if p.MaxHedges <= 0 {
+		return DefaultMaxHedges
+	}
+	return p.MaxHedges
+}
+
+// HedgeDelay returns how long to wait for a reply before sending the next copy
+// HedgeDelay 返回发送下一个副本前等待回复的时间
+func (p *Policy) HedgeDelay() time.Duration {
+	if p.Percentile <= 0 {
+		return p.Delay
+	}
+	minSamples := p.MinSamples
+	if minSamples <= 0 {
+		minSamples = DefaultMinSamples
+	}
+
+	p.mu.Lock()
+	samples := slices.Clone(p.latencies)
+	p.mu.Unlock()
+	if len(samples) < minSamples {
+		return p.Delay
+	}
+	slices.Sort(samples)
+	index := int(p.Percentile*float64(len(samples))+0.5) - 1
+	return samples[min(max(index, 0), len(samples)-1)]
+}
+
+// Observe records the latency of a reply
+// Observe 记录一次回复的耗时
+func (p *Policy) Observe(latency time.Duration) {
+	if p.Percentile <= 0 {
+		return
+	}
+	window := p.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.latencies) < window {
+		p.latencies = append(p.latencies, latency)
+		return
+	}
+	p.latencies[p.next%len(p.latencies)] = latency
+	p.next = (p.next + 1) % len(p.latencies)
+}
@@ -0,0 +1,137 @@
+// Package interceptor provides hooks and middleware around request processing
+// 包 interceptor 提供请求处理前后的钩子和中间件
+package interceptor
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/GoEnthusiast/httpreq/types/request"
+	"github.com/GoEnthusiast/httpreq/types/response"
+)
+
+// Handler processes a request and returns its response
+// Handler 处理请求并返回响应
+type Handler func(ctx context.Context, req *request.Request) *response.Response
+
+// Middleware wraps a Handler around the full round trip, including retries.
+// It may modify the request, replace the response or short-circuit without calling next.
+// Middleware 包装完整往返过程（包括重试）的 Handler，可以修改请求、替换响应，或不调用 next 直接返回。
+type Middleware func(next Handler) Handler
+
+// BeforeRequestFunc is called for every attempt after the HTTP request is built and before it is sent.
+// Returning an error aborts the attempt.
+// BeforeRequestFunc 在每次尝试构建 HTTP 请求之后、发送之前调用，返回错误会中止本次尝试。
+type BeforeRequestFunc func(req *request.Request, httpReq *http.Request) error
+
+// AfterResponseFunc is called once a response has been received successfully and may mutate it.
+// Returning an error sets it as the response error.
+// AfterResponseFunc 在成功收到响应后调用，可以修改响应，返回的错误会被设置为响应错误。
+type AfterResponseFunc func(resp *response.Response) error
+
+// ErrorFunc is called once when a request ends with an error
+// ErrorFunc 在请求以错误结束时调用
+type ErrorFunc func(resp *response.Response)
+
+// Chain holds the registered middlewares and hooks with thread-safe registration
+// Chain 保存已注册的中间件和钩子，提供线程安全的注册
+type Chain struct {
+	middlewares   []Middleware        // Round-trip middlewares, first registered is outermost / 往返中间件，先注册的在最外层
+	beforeRequest []BeforeRequestFunc // Before-request hooks / 请求前钩子
+	afterResponse []AfterResponseFunc // After-response hooks / 响应后钩子
+	onError       []ErrorFunc         // Error hooks / 错误钩子
+	mu            sync.RWMutex        // Mutex for thread safety / 用于线程安全的读写锁
+}
+
+// NewChain creates an empty interceptor chain
+// NewChain 创建一个空的拦截器链
+func NewChain() *Chain {
+	return &Chain{}
+}
+
+// Use registers round-trip middlewares
+// Use 注册往返中间件
+func (c *Chain) Use(middlewares ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// OnBeforeRequest registers a before-request hook
+// OnBeforeRequest 注册请求前钩子
+func (c *Chain) OnBeforeRequest(fn BeforeRequestFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.beforeRequest = append(c.beforeRequest, fn)
+}
+
+// OnAfterResponse registers an after-response hook
+// OnAfterResponse 注册响应后钩子
+func (c *Chain) OnAfterResponse(fn AfterResponseFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.afterResponse = append(c.afterResponse, fn)
+}
+
+// OnError registers an error hook
+// OnError 注册错误钩子
+func (c *Chain) OnError(fn ErrorFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onError = append(c.onError, fn)
+}
+
+// Then wraps handler with the registered middlewares
+// Then 使用已注册的中间件包装 handler
+func (c *Chain) Then(handler Handler) Handler {
+	c.mu.RLock()
+	middlewares := c.middlewares
+	c.mu.RUnlock()
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// BeforeRequest runs the before-request hooks in registration order, stopping at the first error
+// BeforeRequest 按注册顺序执行请求前钩子，遇到第一个错误时停止
+func (c *Chain) BeforeRequest(req *request.Request, httpReq *http.Request) error {
+	c.mu.RLock()
+	hooks := c.beforeRequest
+	c.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(req, httpReq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AfterResponse runs the after-response hooks for a successful response, then the error hooks if it failed
+// AfterResponse 对成功的响应执行响应后钩子，如果响应失败则执行错误钩子
+func (c *Chain) AfterResponse(resp *response.Response) {
+	c.mu.RLock()
+	afterHooks, errorHooks := c.afterResponse, c.onError
+	c.mu.RUnlock()
+
+	if resp.Error == nil {
+		for _, hook := range afterHooks {
+			if err := hook(resp); err != nil {
+				resp.Error = err
+				break
+			}
+		}
+	}
+	if resp.Error != nil {
+		for _, hook := range errorHooks {
+			hook(resp)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+// Package proxypool provides a rotating proxy pool that bans failing proxies, accepted by SetProxy and Request.Proxy
+// 包 proxypool 提供轮换使用的代理池，会封禁失败的代理，可用于 SetProxy 和 Request.Proxy
+package proxypool
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// Defaults used when a Config field is zero
+// Config 字段为零时使用的默认值
+const (
+	DefaultMaxFails      = 3                // Default consecutive failures banning a proxy / 默认使代理被封禁的连续失败次数
+	DefaultBanDuration   = 5 * time.Minute  // Default ban time / 默认封禁时长
+	DefaultProbeInterval = 30 * time.Second // Default time between health probes / 默认健康探测间隔
+	DefaultProbeTimeout  = 10 * time.Second // Default timeout of a health probe / 默认健康探测超时时间
+)
+
+// ErrNoProxy is returned when every proxy of the pool is banned
+// ErrNoProxy 在代理池中所有代理都被封禁时返回
+var ErrNoProxy = errors.New("no proxy available")
+
+// Strategy chooses the proxy of a request
+// Strategy 选择请求的代理
+type Strategy int
+
+const (
+	RoundRobin Strategy = iota // Proxies in turn / 依次轮换代理
+	Random                     // A random proxy / 随机选择代理
+	StickyHost                 // Same target host, same proxy while it is not banned / 相同的目标主机在代理未被封禁时总是使用同一代理
+	StickyKey                  // Same Request.Meta[Config.SessionKey], same proxy while it is not banned / 相同的 Request.Meta[Config.SessionKey] 在代理未被封禁时总是使用同一代理
+)
+
+// Config describes the proxies, how they rotate and when failing ones are banned
+// Config 描述代理、代理的轮换方式以及何时封禁失败的代理
+type Config struct {
+	Proxies       []string                             // Proxy URLs, "host:port" means http://host:port / 代理地址，"host:port" 表示 http://host:port
+	Strategy      Strategy                             // Rotation strategy / 轮换策略
+	SessionKey    string                               // Request.Meta key grouping requests for StickyKey, requests without it rotate / StickyKey 用于分组请求的 Request.Meta 键，没有该键的请求按轮换选择
+	MaxFails      int                                  // Consecutive failures banning a proxy, DefaultMaxFails when zero / 使代理被封禁的连续失败次数，为零时使用 DefaultMaxFails
+	BanDuration   time.Duration                        // Time a banned proxy is skipped, DefaultBanDuration when zero / 被封禁代理被跳过的时间，为零时使用 DefaultBanDuration
+	IsFailure     func(statusCode int, err error) bool // Decides whether a request failed because of its proxy, DefaultFailure when nil / 判断请求是否因代理而失败，为 nil 时使用 DefaultFailure
+	ProbeURL      string                               // URL fetched through every proxy in the background, no probing when empty / 在后台通过每个代理获取的地址，为空时不探测
+	ProbeInterval time.Duration                        // Time between health probes, DefaultProbeInterval when zero / 健康探测间隔，为零时使用 DefaultProbeInterval
+	ProbeTimeout  time.Duration                        // Timeout of a health probe, DefaultProbeTimeout when zero / 健康探测超时时间，为零时使用 DefaultProbeTimeout
+}
+
+// DefaultFailure counts proxy and connect errors, timeouts, 407 Proxy Authentication Required and 403 Forbidden as
+// failures. Errors are only reported when no response arrived, so a timeout means a hung proxy or dial; canceled
+// requests are not counted.
+// DefaultFailure 将代理错误、连接错误、超时、407 Proxy Authentication Required 和 403 Forbidden 计为失败。
+// 只有在没有收到响应时才会报告错误，因此超时意味着代理或拨号无响应；被取消的请求不计入。
+func DefaultFailure(statusCode int, err error) bool {
+	if err != nil {
+		switch reqerr.Classify("", err).Kind {
+		case reqerr.KindProxy, reqerr.KindConnect, reqerr.KindTimeout:
+			return true
+		}
+		return false
+	}
+	return statusCode == http.StatusProxyAuthRequired || statusCode == http.StatusForbidden
+}
+
+// Status is a snapshot of a proxy
+// Status 是代理的快照
+type Status struct {
+	URL         string    // Proxy URL with the password redacted / 隐藏密码的代理地址
+	Requests    int64     // Requests sent through the proxy / 经该代理发送的请求数
+	Failures    int       // Consecutive failures / 连续失败次数
+	Banned      bool      // Whether the proxy is currently skipped / 代理当前是否被跳过
+	BannedUntil time.Time // End of the current ban / 当前封禁的结束时间
+}
+
+// Pool rotates requests over proxies. Pass the pool itself (not its Proxy method) to SetProxy or Request.Proxy,
+// so that it learns the outcome of requests: proxies failing MaxFails times in a row are banned for BanDuration,
+// and a successful health probe lifts the ban early. Call Close to stop probing.
+// Pool 在代理之间轮换请求。请将代理池本身（而不是它的 Proxy 方法）传给 SetProxy 或 Request.Proxy，使其能够了解请求结果：
+// 连续失败 MaxFails 次的代理被封禁 BanDuration 时间，健康探测成功会提前解除封禁。调用 Close 停止探测。
+type Pool struct {
+	config  Config            // Configuration with defaults applied / 应用默认值后的配置
+	proxies []*proxy          // Proxies in configuration order / 按配置顺序排列的代理
+	byURL   map[string]*proxy // Proxies keyed by URL / 按地址索引的代理
+	next    int               // Round robin position / 轮换位置
+	mu      sync.Mutex        // Mutex for proxy state / 代理状态的互斥锁
+	stop    chan struct{}     // Closed by Close / 由 Close 关闭
+	once    sync.Once         // Guards Close / 保护 Close
+}
+
+// proxy is the state of a proxy
+// proxy 是代理的状态
+type proxy struct {
+	url         *url.URL  // Proxy URL / 代理地址
+	requests    int64     // Requests sent through the proxy / 经该代理发送的请求数
+	failures    int       // Consecutive failures / 连续失败次数
+	bannedUntil time.Time // End of the current ban / 当前封禁的结束时间
+}
+
+// New creates a pool from config and starts health probing when ProbeURL is set
+// New 使用 config 创建代理池，设置了 ProbeURL 时开始健康探测
+func New(config Config) (*Pool, error) {
+	if len(config.Proxies) == 0 {
+		return nil, errors.New("proxy pool needs at least one proxy")
+	}
+	if config.MaxFails <= 0 {
+		config.MaxFails = DefaultMaxFails
+	}
+	if config.BanDuration <= 0 {
+		config.BanDuration = DefaultBanDuration
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = DefaultFailure
+	}
+	if config.ProbeInterval <= 0 {
+		config.ProbeInterval = DefaultProbeInterval
+	}
+	if config.ProbeTimeout <= 0 {
+		config.ProbeTimeout = DefaultProbeTimeout
+	}
+
+	p := &Pool{config: config, byURL: make(map[string]*proxy), stop: make(chan struct{})}
+	for _, raw := range config.Proxies {
+		u, err := parseProxyURL(raw)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.byURL[u.String()]; ok {
+			continue
+		}
+		px := &proxy{url: u}
+		p.proxies = append(p.proxies, px)
+		p.byURL[u.String()] = px
+	}
+	if config.ProbeURL != "" {
+		go p.probeLoop()
+	}
+	return p, nil
+}
+
+// Load reads proxy URLs from a file, one per line; blank lines and lines starting with # are skipped
+// Load 从文件中读取代理地址，每行一个；跳过空行和以 # 开头的行
+func Load(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var proxies []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			proxies = append(proxies, line)
+		}
+	}
+	return proxies, scanner.Err()
+}
+
+// Proxy chooses the proxy of req following the strategy, skipping banned proxies; ErrNoProxy when all are banned
+// Proxy 按照策略为 req 选择代理并跳过被封禁的代理；全部被封禁时返回 ErrNoProxy
+func (p *Pool) Proxy(req *http.Request) (*url.URL, error) {
+	var key string
+	switch p.config.Strategy {
+	case StickyHost:
+		key = req.URL.Host
+	case StickyKey:
+		if r := request.FromContext(req.Context()); r != nil && p.config.SessionKey != "" {
+			if value, ok := r.Meta[p.config.SessionKey]; ok {
+				key = fmt.Sprint(value)
+			}
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	available := make([]*proxy, 0, len(p.proxies))
+	for _, px := range p.proxies {
+		if !now.Before(px.bannedUntil) {
+			available = append(available, px)
+		}
+	}
+	if len(available) == 0 {
+		return nil, ErrNoProxy
+	}
+
+	var chosen *proxy
+	switch {
+	case key != "":
+		chosen = rendezvous(key, available)
+	case p.config.Strategy == Random:
+		chosen = available[rand.IntN(len(available))]
+	default:
+		// Walk the whole list, so that banning a proxy does not shift the turn of the others
+		// 遍历完整列表，使封禁代理不会改变其他代理的顺序
+		for i := range p.proxies {
+			if px := p.proxies[(p.next+i)%len(p.proxies)]; !now.Before(px.bannedUntil) {
+				chosen = px
+				p.next = (p.next + i + 1) % len(p.proxies)
+				break
+			}
+		}
+	}
+	chosen.requests++
+	return chosen.url, nil
+}
+
+// ReportProxy records the outcome of a request sent through proxyURL
+// ReportProxy 记录经 proxyURL 发送的请求的结果
+func (p *Pool) ReportProxy(proxyURL *url.URL, statusCode int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if px, ok := p.byURL[proxyURL.String()]; ok {
+		p.recordLocked(px, p.config.IsFailure(statusCode, err))
+	}
+}
+
+// recordLocked counts a success or failure of px, banning it after MaxFails failures in a row
+// recordLocked 记录 px 的一次成功或失败，连续失败 MaxFails 次后封禁
+func (p *Pool) recordLocked(px *proxy, failed bool) {
+	if !failed {
+		px.failures = 0
+		return
+	}
+	if px.failures++; px.failures >= p.config.MaxFails {
+		px.failures = 0
+		px.bannedUntil = time.Now().Add(p.config.BanDuration)
+	}
+}
+
+// Status returns snapshots of the proxies in configuration order, for monitoring
+// Status 按配置顺序返回代理的快照，用于监控
+func (p *Pool) Status() []Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]Status, len(p.proxies))
+	for i, px := range p.proxies {
+		statuses[i] = Status{
+			URL:         px.url.Redacted(),
+			Requests:    px.requests,
+			Failures:    px.failures,
+			Banned:      now.Before(px.bannedUntil),
+			BannedUntil: px.bannedUntil,
+		}
+	}
+	return statuses
+}
+
+// Close stops health probing
+// Close 停止健康探测
+func (p *Pool) Close() {
+	p.once.Do(func() { close(p.stop) })
+}
+
+// probeLoop probes every proxy each ProbeInterval until Close is called
+// probeLoop 每隔 ProbeInterval 探测所有代理，直到调用 Close
+func (p *Pool) probeLoop() {
+	ticker := time.NewTicker(p.config.ProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.probeAll()
+		}
+	}
+}
+
+// probeAll fetches ProbeURL through every proxy concurrently; a successful probe lifts a ban,
+// a failed one counts as a failure
+// probeAll 并发地通过每个代理获取 ProbeURL；探测成功会解除封禁，失败计为一次失败
+func (p *Pool) probeAll() {
+	var wg sync.WaitGroup
+	for _, px := range p.proxies {
+		wg.Add(1)
+		go func(px *proxy) {
+			defer wg.Done()
+			statusCode, err := p.probe(px.url)
+			failed := err != nil || statusCode >= http.StatusInternalServerError || p.config.IsFailure(statusCode, nil)
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if !failed {
+				px.bannedUntil = time.Time{}
+			}
+			p.recordLocked(px, failed)
+		}(px)
+	}
+	wg.Wait()
+}
+
+// probe fetches ProbeURL through proxyURL and returns the status code
+// probe 通过 proxyURL 获取 ProbeURL 并返回状态码
+func (p *Pool) probe(proxyURL *url.URL) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.ProbeTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	transport := &http.Transport{Proxy: http.ProxyURL(proxyURL), DisableKeepAlives: true}
+	defer transport.CloseIdleConnections()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.ProbeURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// parseProxyURL parses a proxy URL, "host:port" means http://host:port
+// parseProxyURL 解析代理地址，"host:port" 表示 http://host:port
+func parseProxyURL(raw string) (*url.URL, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse proxy url error: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %s", u.Redacted())
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy url has no host: %s", u.Redacted())
+	}
+	return u, nil
+}
+
+// rendezvous returns the proxy with the highest hash for key, so a key keeps its proxy while that one is available
+// and only keys of a banned proxy move
+// rendezvous 返回对 key 哈希值最大的代理，使 key 在其代理可用时保持不变，且只有被封禁代理的 key 会迁移
+func rendezvous(key string, available []*proxy) *proxy {
+	var (
+		chosen *proxy
+		best   uint64
+	)
+	for _, px := range available {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(px.url.String()))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(key))
+		if sum := h.Sum64(); chosen == nil || sum > best {
+			chosen, best = px, sum
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,220 @@
+// Package ratelimit provides token bucket rate limiters for HTTP requests
+// 包 ratelimit 提供 HTTP 请求的令牌桶限流器
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// sweepThreshold is the number of buckets above which idle full buckets are dropped
+// sweepThreshold 是桶数量的阈值，超过后会清理已满的空闲桶
+const sweepThreshold = 4096
+
+// KeyFunc derives the bucket key of a request, host is the host of the resolved request URL.
+// Requests with an empty key are not limited.
+// KeyFunc 生成请求所属桶的键，host 为解析后请求地址的主机。键为空的请求不受限制。
+type KeyFunc func(host string, req *request.Request) string
+
+// ByHost keys requests by host
+// ByHost 按主机划分请求
+func ByHost(host string, _ *request.Request) string {
+	return host
+}
+
+// ByMeta keys requests by the value of req.Meta[name], requests without it are not limited
+// ByMeta 按 req.Meta[name] 的值划分请求，没有该值的请求不受限制
+func ByMeta(name string) KeyFunc {
+	return func(_ string, req *request.Request) string {
+		value, ok := req.Meta[name]
+		if !ok || value == nil {
+			return ""
+		}
+		return fmt.Sprint(value)
+	}
+}
+
+// Limit is the rate and burst of a bucket
+// Limit 是一个桶的速率和突发容量
+type Limit struct {
+	Rate  float64 // Requests per second, 0 or less means unlimited / 每秒请求数，小于等于 0 表示不限制
+	Burst int     // Maximum number of requests sent at once, at least 1 / 一次最多发送的请求数，至少为 1
+}
+
+// Limiter is a set of token buckets, one per key. Every attempt of a request, retries included, takes one token.
+// Limiter 是一组令牌桶，每个键一个。请求的每次尝试（包括重试）都会消耗一个令牌。
+type Limiter struct {
+	limit   Limit              // Default limit of every key / 每个键的默认限制
+	key     KeyFunc            // Bucket key of a request, nil means a single global bucket / 请求所属桶的键，nil 表示单个全局桶
+	limits  map[string]Limit   // Limits overriding the default for some keys / 覆盖部分键默认限制的限制
+	buckets map[string]*bucket // Buckets by key / 按键划分的桶
+	mu      sync.Mutex         // Mutex for buckets and limits / 桶和限制的互斥锁
+}
+
+// NewLimiter creates a limiter allowing rate requests per second with bursts of burst requests for each key returned by key.
+// A nil key limits all requests with one bucket.
+// NewLimiter 创建一个限流器，key 返回的每个键每秒允许 rate 个请求，突发容量为 burst。key 为 nil 时所有请求共用一个桶。
+func NewLimiter(rate float64, burst int, key KeyFunc) *Limiter {
+	return &Limiter{
+		limit:   Limit{Rate: rate, Burst: burst},
+		key:     key,
+		limits:  make(map[string]Limit),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Global creates a limiter shared by all requests
+// Global 创建一个所有请求共享的限流器
+func Global(rate float64, burst int) *Limiter {
+	return NewLimiter(rate, burst, nil)
+}
+
+// PerHost creates a limiter with one bucket per host
+// PerHost 创建一个每个主机一个桶的限流器
+func PerHost(rate float64, burst int) *Limiter {
+	return NewLimiter(rate, burst, ByHost)
+}
+
+// SetLimit overrides the limit of one key, e.g. a host of a PerHost limiter
+// SetLimit 覆盖某个键的限制，例如 PerHost 限流器中的某个主机
+func (l *Limiter) SetLimit(key string, rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := Limit{Rate: rate, Burst: burst}
+	l.limits[key] = limit
+	if b, ok := l.buckets[key]; ok {
+		b.setLimit(limit, time.Now())
+	}
+}
+
+// Wait blocks until req may be sent to host and returns the time waited.
+// If ctx ends first, the token is given back and the context error is returned.
+// Wait 阻塞直到 req 可以发送到 host，并返回等待的时间。如果 ctx 先结束，则归还令牌并返回上下文错误。
+func (l *Limiter) Wait(ctx context.Context, host string, req *request.Request) (time.Duration, error) {
+	key := ""
+	if l.key != nil {
+		if key = l.key(host, req); key == "" {
+			return 0, nil
+		}
+	}
+
+	start := time.Now()
+	b, delay := l.reserve(key, start)
+	if delay <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return time.Since(start), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		b.cancel()
+		l.mu.Unlock()
+		return time.Since(start), ctx.Err()
+	}
+}
+
+// reserve takes a token from the bucket of key and returns how long to wait for it
+// reserve 从 key 对应的桶中取一个令牌，并返回需要等待的时间
+func (l *Limiter) reserve(key string, now time.Time) (*bucket, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= sweepThreshold {
+			l.sweep(now)
+		}
+		limit, ok := l.limits[key]
+		if !ok {
+			limit = l.limit
+		}
+		b = newBucket(limit, now)
+		l.buckets[key] = b
+	}
+	return b, b.reserve(now)
+}
+
+// sweep drops buckets that are full again, they behave like new ones
+// sweep 清理已经重新装满的桶，它们与新建的桶行为相同
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.full(now) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// bucket is a token bucket, tokens go negative while requests are waiting
+// bucket 是一个令牌桶，有请求等待时令牌数为负
+type bucket struct {
+	rate   float64   // Tokens added per second / 每秒增加的令牌数
+	burst  float64   // Bucket capacity / 桶容量
+	tokens float64   // Tokens available at last / last 时刻可用的令牌数
+	last   time.Time // Time tokens was last updated / 令牌数最后更新的时间
+}
+
+// newBucket creates a full bucket
+// newBucket 创建一个已满的桶
+func newBucket(limit Limit, now time.Time) *bucket {
+	b := &bucket{last: now}
+	b.setLimit(limit, now)
+	b.tokens = b.burst
+	return b
+}
+
+// setLimit changes the rate and burst, keeping the tokens accumulated so far
+// setLimit 修改速率和突发容量，保留已积累的令牌
+func (b *bucket) setLimit(limit Limit, now time.Time) {
+	b.advance(now)
+	b.rate = limit.Rate
+	if b.rate <= 0 {
+		b.rate = math.Inf(1)
+	}
+	b.burst = float64(max(limit.Burst, 1))
+	b.tokens = math.Min(b.tokens, b.burst)
+}
+
+// advance adds the tokens accumulated since last
+// advance 增加自 last 以来积累的令牌
+func (b *bucket) advance(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// reserve takes a token and returns how long until it is available
+// reserve 取一个令牌，并返回距离该令牌可用的时间
+func (b *bucket) reserve(now time.Time) time.Duration {
+	if math.IsInf(b.rate, 1) {
+		return 0
+	}
+	b.advance(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back a reserved token
+// cancel 归还一个已预留的令牌
+func (b *bucket) cancel() {
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// full reports whether the bucket is full at now
+// full 报告桶在 now 时刻是否已满
+func (b *bucket) full(now time.Time) bool {
+	b.advance(now)
+	return b.tokens >= b.burst
+}
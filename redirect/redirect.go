@@ -0,0 +1,108 @@
+// Package redirect provides redirect policies for HTTP requests
+// 包 redirect 提供 HTTP 请求的重定向策略
+package redirect
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/GoEnthusiast/httpreq/reqerr"
+)
+
+// DefaultMaxRedirects is the number of redirects followed when a policy does not set MaxRedirects
+// DefaultMaxRedirects 是策略未设置 MaxRedirects 时跟随的重定向次数
+const DefaultMaxRedirects = 10
+
+// AuthMode controls how authentication headers are carried across redirects
+// AuthMode 控制认证相关请求头在重定向时的传递方式
+type AuthMode int
+
+const (
+	AuthDefault  AuthMode = iota // Strip when redirected to a domain that is not a subdomain (net/http behavior) / 重定向到非子域名时移除（net/http 默认行为）
+	AuthPreserve                 // Always send them, even to other hosts / 始终发送，包括其他主机
+	AuthStrip                    // Strip whenever the host changes / 只要主机发生变化就移除
+)
+
+// sensitiveHeaders are the authentication headers affected by AuthMode
+// sensitiveHeaders 是受 AuthMode 影响的认证相关请求头
+var sensitiveHeaders = []string{"Authorization", "Www-Authenticate", "Cookie", "Cookie2"}
+
+// Policy describes which redirects are followed
+// Policy 描述跟随哪些重定向
+type Policy struct {
+	Disable      bool     // Do not follow redirects, the 3xx response is returned as is / 不跟随重定向，直接返回 3xx 响应
+	MaxRedirects int      // Maximum number of redirects, DefaultMaxRedirects when zero / 最大重定向次数，为零时使用 DefaultMaxRedirects
+	SameHostOnly bool     // Only follow redirects to the same host, others return the 3xx response / 只跟随同一主机的重定向，其他返回 3xx 响应
+	Auth         AuthMode // Authentication headers across hosts / 跨主机时的认证请求头处理
+	NoRepost     bool     // Do not follow 307/308 redirects that would send the request body again / 不跟随会重新发送请求体的 307/308 重定向
+}
+
+// NewPolicy creates a policy that follows at most maxRedirects redirects
+// NewPolicy 创建一个最多跟随 maxRedirects 次重定向的策略
+func NewPolicy(maxRedirects int) *Policy {
+	return &Policy{MaxRedirects: maxRedirects}
+}
+
+// NoFollow creates a policy that does not follow redirects
+// NoFollow 创建一个不跟随重定向的策略
+func NoFollow() *Policy {
+	return &Policy{Disable: true}
+}
+
+// Check decides whether req, the next request of a redirect chain, is sent. via holds the requests already made, oldest first.
+// It returns http.ErrUseLastResponse to stop and return the redirect response, or an error of kind reqerr.KindRedirect
+// when the redirect limit is exceeded. A nil policy follows up to DefaultMaxRedirects redirects.
+// Check 判断重定向链中的下一个请求 req 是否发送，via 为已发送的请求（按时间顺序）。
+// 返回 http.ErrUseLastResponse 表示停止并返回重定向响应，超过重定向次数上限时返回 reqerr.KindRedirect 类型的错误。
+// nil 策略最多跟随 DefaultMaxRedirects 次重定向。
+func (p *Policy) Check(req *http.Request, via []*http.Request) error {
+	if p == nil {
+		p = &Policy{}
+	}
+	if p.Disable {
+		return http.ErrUseLastResponse
+	}
+	maxRedirects := p.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+	if len(via) > maxRedirects {
+		return reqerr.New(reqerr.KindRedirect, fmt.Sprintf("stopped after %d redirects", maxRedirects), nil)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return reqerr.New(reqerr.KindRedirect, fmt.Sprintf("unsupported redirect scheme %q", req.URL.Scheme), nil)
+	}
+
+	prev := via[len(via)-1]
+	sameHost := strings.EqualFold(req.URL.Host, prev.URL.Host)
+	if p.SameHostOnly && !sameHost {
+		return http.ErrUseLastResponse
+	}
+	if p.NoRepost && req.Response != nil && hasBody(req) &&
+		(req.Response.StatusCode == http.StatusTemporaryRedirect || req.Response.StatusCode == http.StatusPermanentRedirect) {
+		return http.ErrUseLastResponse
+	}
+
+	switch p.Auth {
+	case AuthPreserve:
+		for _, key := range sensitiveHeaders {
+			if values, ok := via[0].Header[key]; ok && req.Header.Get(key) == "" {
+				req.Header[key] = values
+			}
+		}
+	case AuthStrip:
+		if !sameHost {
+			for _, key := range sensitiveHeaders {
+				req.Header.Del(key)
+			}
+		}
+	}
+	return nil
+}
+
+// hasBody reports whether req sends a request body
+// hasBody 报告 req 是否发送请求体
+func hasBody(req *http.Request) bool {
+	return req.ContentLength > 0 || (req.Body != nil && req.Body != http.NoBody)
+}
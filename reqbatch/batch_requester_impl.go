@@ -1,6 +1,8 @@
 package reqbatch
 
 import (
+	"context"
+
 	"github.com/GoEnthusiast/httpreq/core"
 	"github.com/GoEnthusiast/httpreq/types/request"
 	"github.com/GoEnthusiast/httpreq/types/response"
@@ -15,6 +17,12 @@ type BatchRequesterImpl struct {
 // Do executes multiple HTTP requests concurrently and returns all responses
 // Do 并发执行多个 HTTP 请求并返回所有响应
 func (s *BatchRequesterImpl) Do(reqs []*request.Request) []*response.Response {
+	return s.DoContext(context.Background(), reqs)
+}
+
+// DoContext executes multiple HTTP requests concurrently bound to ctx and returns all responses
+// DoContext 并发执行绑定到 ctx 的多个 HTTP 请求并返回所有响应
+func (s *BatchRequesterImpl) DoContext(ctx context.Context, reqs []*request.Request) []*response.Response {
 	var (
 		// Buffered channel to collect responses
 		// 带缓冲的通道，用于收集响应
@@ -26,7 +34,7 @@ func (s *BatchRequesterImpl) Do(reqs []*request.Request) []*response.Response {
 	for i := range reqs {
 		req := reqs[i] // Avoid goroutine closure reference error / 避免 goroutine 闭包引用错误
 		go func(r *request.Request) {
-			resp := s.RequestHandler.ProcessRequest(r)
+			resp := s.RequestHandler.ProcessRequestContext(ctx, r)
 			respCh <- resp
 		}(req)
 	}
@@ -40,10 +48,10 @@ func (s *BatchRequesterImpl) Do(reqs []*request.Request) []*response.Response {
 	return responses
 }
 
-// NewBatchRequester creates a new batch request handler with optional HTTP/2 support
-// NewBatchRequester 创建一个新的批量请求处理器，支持可选的 HTTP/2
-func NewBatchRequester(enableHttp2 bool) BatchRequester {
+// NewBatchRequester creates a new batch request handler with optional HTTP/2 support and options such as core.WithBaseURL
+// NewBatchRequester 创建一个新的批量请求处理器，支持可选的 HTTP/2 和 core.WithBaseURL 等配置项
+func NewBatchRequester(enableHttp2 bool, opts ...core.Option) BatchRequester {
 	return &BatchRequesterImpl{
-		RequestHandler: core.NewRequestHandler(enableHttp2),
+		RequestHandler: core.NewRequestHandler(enableHttp2, opts...),
 	}
 }
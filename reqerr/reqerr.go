@@ -0,0 +1,229 @@
+// Package reqerr provides the typed error taxonomy reported on responses
+// 包 reqerr 提供报告在响应中的类型化错误分类
+package reqerr
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// Kind classifies why a request failed
+// Kind 对请求失败的原因进行分类
+type Kind int
+
+// Error kinds
+// 错误类型
+const (
+	KindUnknown        Kind = iota // Unclassified error / 未分类错误
+	KindBodyBuild                  // Request body could not be built / 无法构建请求体
+	KindInvalidRequest             // Request is malformed (URL, method, hook rejection) / 请求格式错误（地址、方法、钩子拒绝）
+	KindProxy                      // Proxy configuration or proxy connection failed / 代理配置或代理连接失败
+	KindDNS                        // Host name resolution failed / 主机名解析失败
+	KindConnect                    // TCP connection failed / TCP 连接失败
+	KindTLS                        // TLS handshake or certificate verification failed / TLS 握手或证书校验失败
+	KindTimeout                    // A timeout expired / 超时
+	KindRedirect                   // Redirect policy was violated / 违反重定向策略
+	KindAuth                       // Credentials could not be obtained / 无法获取认证信息
+	KindTransport                  // Connection failed after it was established / 连接建立后失败
+	KindBodyRead                   // Response body could not be read / 无法读取响应体
+	KindTooLarge                   // Response body exceeded the size limit / 响应体超过大小限制
+	KindCanceled                   // Request context was canceled / 请求上下文被取消
+	KindCircuitOpen                // Circuit breaker of the host is open, the request was not sent / 主机的熔断器处于打开状态，请求未发送
+)
+
+// String returns the name of the kind
+// String 返回错误类型名称
+func (k Kind) String() string {
+	switch k {
+	case KindBodyBuild:
+		return "body build"
+	case KindInvalidRequest:
+		return "invalid request"
+	case KindProxy:
+		return "proxy"
+	case KindDNS:
+		return "dns"
+	case KindConnect:
+		return "connect"
+	case KindTLS:
+		return "tls"
+	case KindTimeout:
+		return "timeout"
+	case KindRedirect:
+		return "redirect"
+	case KindAuth:
+		return "auth"
+	case KindTransport:
+		return "transport"
+	case KindBodyRead:
+		return "body read"
+	case KindTooLarge:
+		return "body too large"
+	case KindCanceled:
+		return "canceled"
+	case KindCircuitOpen:
+		return "circuit open"
+	default:
+		return "unknown"
+	}
+}
+
+// Sentinel errors, one per kind, matching any *Error of that kind with errors.Is
+// 哨兵错误，每种类型一个，可通过 errors.Is 匹配该类型的任意 *Error
+var (
+	ErrBodyBuild      = &Error{Kind: KindBodyBuild}
+	ErrInvalidRequest = &Error{Kind: KindInvalidRequest}
+	ErrProxy          = &Error{Kind: KindProxy}
+	ErrDNS            = &Error{Kind: KindDNS}
+	ErrConnect        = &Error{Kind: KindConnect}
+	ErrTLS            = &Error{Kind: KindTLS}
+	ErrTimeout        = &Error{Kind: KindTimeout}
+	ErrRedirect       = &Error{Kind: KindRedirect}
+	ErrAuth           = &Error{Kind: KindAuth}
+	ErrTransport      = &Error{Kind: KindTransport}
+	ErrBodyRead       = &Error{Kind: KindBodyRead}
+	ErrTooLarge       = &Error{Kind: KindTooLarge}
+	ErrCanceled       = &Error{Kind: KindCanceled}
+	ErrCircuitOpen    = &Error{Kind: KindCircuitOpen}
+)
+
+// Error is a classified request error that preserves its cause
+// Error 是保留原始原因的分类请求错误
+type Error struct {
+	Kind Kind   // Error kind / 错误类型
+	Op   string // Description of the failed operation / 失败操作的描述
+	Err  error  // Underlying cause / 底层原因
+}
+
+// New creates a classified error
+// New 创建一个分类错误
+func New(kind Kind, op string, err error) *Error {
+	return &Error{Kind: kind, Op: op, Err: err}
+}
+
+// Error returns the error message
+// Error 返回错误信息
+func (e *Error) Error() string {
+	switch {
+	case e.Op == "" && e.Err == nil:
+		return e.Kind.String()
+	case e.Err == nil:
+		return e.Op
+	case e.Op == "":
+		return e.Err.Error()
+	}
+	return e.Op + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying cause
+// Unwrap 返回底层原因
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the kind sentinels, so errors.Is(err, ErrTimeout) holds for every timeout error
+// Is 匹配类型哨兵错误，使任何超时错误都满足 errors.Is(err, ErrTimeout)
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Op == "" && t.Err == nil && t.Kind == e.Kind
+}
+
+// Temporary reports whether the failure is likely transient (timeouts, connection failures, temporary DNS errors)
+// Temporary 报告失败是否可能是暂时的（超时、连接失败、临时 DNS 错误）
+func (e *Error) Temporary() bool {
+	switch e.Kind {
+	case KindTimeout, KindConnect, KindTransport, KindBodyRead:
+		return true
+	case KindDNS:
+		var dnsErr *net.DNSError
+		if errors.As(e.Err, &dnsErr) {
+			return dnsErr.IsTemporary || dnsErr.IsTimeout
+		}
+	}
+	return false
+}
+
+// Retryable reports whether sending the request again may succeed
+// Retryable 报告再次发送请求是否可能成功
+func (e *Error) Retryable() bool {
+	switch e.Kind {
+	case KindProxy:
+		// Connection failures to the proxy are retryable, invalid proxy settings are not
+		// 代理连接失败可以重试，无效的代理配置不可以
+		var opErr *net.OpError
+		return errors.As(e.Err, &opErr)
+	case KindDNS:
+		var dnsErr *net.DNSError
+		return !errors.As(e.Err, &dnsErr) || !dnsErr.IsNotFound
+	}
+	return e.Temporary()
+}
+
+// KindOf returns the kind of err, KindUnknown if err is not a classified error
+// KindOf 返回 err 的类型，err 不是分类错误时返回 KindUnknown
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindUnknown
+}
+
+// IsRetryable reports whether err is a classified error that may succeed when retried
+// IsRetryable 报告 err 是否为重试后可能成功的分类错误
+func IsRetryable(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Retryable()
+}
+
+// IsTemporary reports whether err is a classified transient error
+// IsTemporary 报告 err 是否为暂时性的分类错误
+func IsTemporary(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Temporary()
+}
+
+// Classify wraps an error returned while sending a request with the kind inferred from its cause
+// Classify 根据原因推断类型，包装发送请求时返回的错误
+func Classify(op string, err error) *Error {
+	return New(classify(err), op, err)
+}
+
+// classify infers the kind of a transport error
+// classify 推断传输错误的类型
+func classify(err error) Kind {
+	var (
+		e            *Error
+		netErr       net.Error
+		dnsErr       *net.DNSError
+		opErr        *net.OpError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	switch {
+	case errors.As(err, &e):
+		return e.Kind
+	case errors.Is(err, context.Canceled):
+		return KindCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return KindTimeout
+	case errors.As(err, &verifyErr), errors.As(err, &authorityErr), errors.As(err, &hostnameErr),
+		errors.As(err, &invalidErr), errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return KindTLS
+	case errors.As(err, &opErr) && opErr.Op == "proxyconnect":
+		return KindProxy
+	case errors.As(err, &dnsErr):
+		return KindDNS
+	case errors.As(err, &opErr) && opErr.Op == "dial", errors.Is(err, syscall.ECONNREFUSED):
+		return KindConnect
+	}
+	return KindTransport
+}
@@ -1,6 +1,8 @@
 package reqsingle
 
 import (
+	"context"
+
 	"github.com/GoEnthusiast/httpreq/core"
 	"github.com/GoEnthusiast/httpreq/types/request"
 	"github.com/GoEnthusiast/httpreq/types/response"
@@ -18,10 +20,16 @@ func (s *SingleRequesterImpl) Do(req *request.Request) *response.Response {
 	return s.RequestHandler.ProcessRequest(req)
 }
 
-// NewSingleRequester creates a new single request handler with optional HTTP/2 support
-// NewSingleRequester 创建一个新的单次请求处理器，支持可选的 HTTP/2
-func NewSingleRequester(enableHttp2 bool) SingleRequester {
+// DoContext executes a single HTTP request bound to ctx and returns the response
+// DoContext 执行绑定到 ctx 的单个 HTTP 请求并返回响应
+func (s *SingleRequesterImpl) DoContext(ctx context.Context, req *request.Request) *response.Response {
+	return s.RequestHandler.ProcessRequestContext(ctx, req)
+}
+
+// NewSingleRequester creates a new single request handler with optional HTTP/2 support and options such as core.WithBaseURL
+// NewSingleRequester 创建一个新的单次请求处理器，支持可选的 HTTP/2 和 core.WithBaseURL 等配置项
+func NewSingleRequester(enableHttp2 bool, opts ...core.Option) SingleRequester {
 	return &SingleRequesterImpl{
-		RequestHandler: core.NewRequestHandler(enableHttp2),
+		RequestHandler: core.NewRequestHandler(enableHttp2, opts...),
 	}
 }
@@ -3,9 +3,22 @@
 package reqstream
 
 import (
+	"context"
 	"net/http"
 	"time"
 
+	"github.com/GoEnthusiast/httpreq/auth"
+	"github.com/GoEnthusiast/httpreq/balancer"
+	"github.com/GoEnthusiast/httpreq/breaker"
+	"github.com/GoEnthusiast/httpreq/cache"
+	"github.com/GoEnthusiast/httpreq/hedge"
+	"github.com/GoEnthusiast/httpreq/interceptor"
+	"github.com/GoEnthusiast/httpreq/ratelimit"
+	"github.com/GoEnthusiast/httpreq/redirect"
+	"github.com/GoEnthusiast/httpreq/resolver"
+	"github.com/GoEnthusiast/httpreq/retry"
+	"github.com/GoEnthusiast/httpreq/signer"
+	"github.com/GoEnthusiast/httpreq/transportsetting"
 	"github.com/GoEnthusiast/httpreq/types/request"
 	"github.com/GoEnthusiast/httpreq/types/response"
 )
@@ -17,6 +30,10 @@ type StreamRequester interface {
 	// Do 将请求提交到流中进行处理
 	Do(req *request.Request)
 
+	// DoContext submits a request bound to ctx to the stream for processing
+	// DoContext 将绑定到 ctx 的请求提交到流中进行处理
+	DoContext(ctx context.Context, req *request.Request)
+
 	// ResponseCh returns a channel for receiving responses
 	// ResponseCh 返回用于接收响应的通道
 	ResponseCh() <-chan *response.Response
@@ -60,4 +77,127 @@ type StreamRequester interface {
 	// SetDisableKeepAlives sets whether to disable HTTP Keep-Alive
 	// SetDisableKeepAlives 设置是否禁用 HTTP Keep-Alive
 	SetDisableKeepAlives(disableKeepAlives bool)
+
+	// SetMaxProxyTransports sets the maximum number of per-proxy transports kept in the pool. It is a soft cap: when every
+	// pooled transport has a request in flight a new one is still created, and the pool shrinks back as requests finish.
+	// SetMaxProxyTransports 设置池中保留的按代理划分的传输层最大数量。这是一个软上限：所有池化传输层都有进行中的请求时
+	// 仍会创建新的传输层，请求结束后传输层池会缩减回上限。
+	SetMaxProxyTransports(maxProxyTransports int)
+
+	// SetProxyTransportIdleTimeout sets how long an unused per-proxy transport is kept before eviction
+	// SetProxyTransportIdleTimeout 设置未使用的按代理划分的传输层在被淘汰前保留的时间
+	SetProxyTransportIdleTimeout(proxyTransportIdleTimeout time.Duration)
+
+	// SetResolver sets the resolver host names are resolved with when dialing, e.g. a resolver.Cache or a
+	// resolver.NewServerResolver; nil uses the system resolver
+	// SetResolver 设置拨号时解析主机名使用的解析器，例如 resolver.Cache 或 resolver.NewServerResolver；nil 表示使用系统解析器
+	SetResolver(r resolver.Resolver)
+
+	// SetResolveOverrides sets the host:port to IP overrides used by every request instead of resolving, like curl --resolve.
+	// Keys are "host:port" or "host" for any port, values an IP or "ip:port"; nil removes them.
+	// SetResolveOverrides 设置所有请求使用的 host:port 到 IP 的覆盖，代替域名解析，类似 curl --resolve。
+	// 键为 "host:port" 或表示任意端口的 "host"，值为 IP 或 "ip:port"；nil 表示移除。
+	SetResolveOverrides(overrides map[string]string)
+
+	// SetDialContext sets the hook dialing every TCP connection, including Unix sockets, instead of the default dialer.
+	// Connect timeouts, resolve overrides and custom resolvers still apply; nil restores the default dialer.
+	// HTTP/3 connections are dialed over QUIC and do not go through the hook.
+	// SetDialContext 设置拨号所有 TCP 连接（包括 Unix 套接字）的钩子，代替默认拨号器。
+	// 连接超时、解析覆盖和自定义解析器仍然生效；nil 表示恢复默认拨号器。HTTP/3 连接通过 QUIC 拨号，不经过该钩子。
+	SetDialContext(dial transportsetting.DialContextFunc)
+
+	// SetUnixSocket sends requests to host ("host:port", or "host" for any port) over the Unix socket at socketPath,
+	// an empty socketPath removes the mapping
+	// SetUnixSocket 使发往 host（"host:port"，或表示任意端口的 "host"）的请求通过 socketPath 处的 Unix 套接字发送，
+	// socketPath 为空表示移除该映射
+	SetUnixSocket(host, socketPath string)
+
+	// SetHTTP3 selects when direct https requests are sent over HTTP/3 (QUIC), see transportsetting.HTTP3Mode
+	// SetHTTP3 选择何时通过 HTTP/3（QUIC）发送直连 https 请求，见 transportsetting.HTTP3Mode
+	SetHTTP3(mode transportsetting.HTTP3Mode)
+
+	// SetTimeout sets the default total timeout used when a request does not set its own
+	// SetTimeout 设置请求未单独指定时使用的默认总超时时间
+	SetTimeout(timeout time.Duration)
+
+	// SetConnectTimeout sets the default TCP connect timeout used when a request does not set its own
+	// SetConnectTimeout 设置请求未单独指定时使用的默认 TCP 连接超时时间
+	SetConnectTimeout(connectTimeout time.Duration)
+
+	// SetResponseHeaderTimeout sets the default response header timeout used when a request does not set its own
+	// SetResponseHeaderTimeout 设置请求未单独指定时使用的默认响应头超时时间
+	SetResponseHeaderTimeout(headerTimeout time.Duration)
+
+	// SetRetryPolicy sets the default retry policy used when a request does not set its own, nil disables retries
+	// SetRetryPolicy 设置请求未单独指定时使用的默认重试策略，nil 表示禁用重试
+	SetRetryPolicy(policy *retry.Policy)
+
+	// SetRedirectPolicy sets the default redirect policy used when a request does not set its own, nil follows up to 10 redirects
+	// SetRedirectPolicy 设置请求未单独指定时使用的默认重定向策略，nil 表示最多跟随 10 次重定向
+	SetRedirectPolicy(policy *redirect.Policy)
+
+	// SetHedgePolicy sets the default hedging policy used when a request does not set its own, nil disables hedging
+	// SetHedgePolicy 设置请求未单独指定时使用的默认对冲策略，nil 表示禁用对冲
+	SetHedgePolicy(policy *hedge.Policy)
+
+	// SetCookieJar sets the cookie jar shared by all requests, nil disables cookie handling
+	// SetCookieJar 设置所有请求共享的 Cookie 容器，nil 表示不处理 Cookie
+	SetCookieJar(jar http.CookieJar)
+
+	// SetBaseURL sets the base URL relative request URLs are appended to, an empty string removes it
+	// SetBaseURL 设置相对请求地址拼接的基础地址，空字符串表示移除
+	SetBaseURL(baseURL string) error
+
+	// SetLoadBalancer sets the load balancer spreading requests with relative URLs over upstream endpoints.
+	// It takes precedence over the base URL, nil disables it.
+	// SetLoadBalancer 设置将相对地址请求分散到各个上游端点的负载均衡器，优先于基础地址，nil 表示禁用
+	SetLoadBalancer(b *balancer.Balancer)
+
+	// SetHeader sets a default header sent with every request, request headers with the same key take precedence
+	// SetHeader 设置每个请求都发送的默认请求头，请求中相同的请求头优先
+	SetHeader(key, value string)
+
+	// DelHeader removes a default header
+	// DelHeader 删除一个默认请求头
+	DelHeader(key string)
+
+	// SetAuth sets the default authentication provider used when a request does not set its own, nil disables it
+	// SetAuth 设置请求未单独指定时使用的默认认证提供者，nil 表示禁用
+	SetAuth(provider auth.Provider)
+
+	// SetSigner sets the default request signer used when a request does not set its own, nil disables signing
+	// SetSigner 设置请求未单独指定时使用的默认请求签名器，nil 表示不签名
+	SetSigner(s signer.Signer)
+
+	// SetRateLimit sets the rate limiters every attempt waits for, replacing the previous ones; no limiters removes rate limiting
+	// SetRateLimit 设置每次尝试都要等待的限流器，替换之前的设置；不传限流器表示取消限流
+	SetRateLimit(limiters ...*ratelimit.Limiter)
+
+	// SetCircuitBreaker sets the per-host circuit breaker every attempt goes through, nil disables it
+	// SetCircuitBreaker 设置每次尝试都要经过的按主机熔断器，nil 表示禁用
+	SetCircuitBreaker(b *breaker.Breaker)
+
+	// SetCache sets the HTTP response cache used by GET requests, nil disables caching
+	// SetCache 设置 GET 请求使用的 HTTP 响应缓存，nil 表示禁用缓存
+	SetCache(c *cache.Cache)
+
+	// Use registers middlewares wrapping the full round trip, the first registered is outermost
+	// Use 注册包装完整往返过程的中间件，先注册的在最外层
+	Use(middlewares ...interceptor.Middleware)
+
+	// OnBeforeRequest registers a hook called for every attempt before the HTTP request is sent
+	// OnBeforeRequest 注册在每次尝试发送 HTTP 请求之前调用的钩子
+	OnBeforeRequest(fn interceptor.BeforeRequestFunc)
+
+	// OnAfterResponse registers a hook called when a response has been received successfully
+	// OnAfterResponse 注册在成功收到响应后调用的钩子
+	OnAfterResponse(fn interceptor.AfterResponseFunc)
+
+	// OnError registers a hook called when a request ends with an error
+	// OnError 注册在请求以错误结束时调用的钩子
+	OnError(fn interceptor.ErrorFunc)
+
+	// SetMaxBodySize sets the default maximum response body size in bytes, 0 means unlimited
+	// SetMaxBodySize 设置默认的最大响应体字节数，0 表示不限制
+	SetMaxBodySize(maxBodySize int64)
 }
@@ -1,38 +1,63 @@
 package reqstream
 
 import (
+	"context"
+
 	"github.com/GoEnthusiast/httpreq/core"
 	"github.com/GoEnthusiast/httpreq/types/request"
 	"github.com/GoEnthusiast/httpreq/types/response"
 )
 
+// job couples a submitted request with the context it was submitted under
+// job 将提交的请求与其提交时的上下文绑定
+type job struct {
+	ctx context.Context  // Request context / 请求上下文
+	req *request.Request // Submitted request / 提交的请求
+}
+
 // StreamRequesterImpl implements the StreamRequester interface
 // StreamRequesterImpl 实现 StreamRequester 接口
 type StreamRequesterImpl struct {
 	*core.RequestHandler                         // Embedded request handler / 嵌入的请求处理器
-	reqCh                chan *request.Request   // Channel for incoming requests / 接收请求的通道
+	reqCh                chan *job               // Channel for incoming requests / 接收请求的通道
 	respCh               chan *response.Response // Channel for outgoing responses / 发送响应的通道
 }
 
 // worker processes requests from the request channel
 // worker 处理来自请求通道的请求
 func (s *StreamRequesterImpl) worker() {
-	for req := range s.reqCh {
-		s.handleRequest(req)
+	for j := range s.reqCh {
+		s.handleRequest(j)
 	}
 }
 
 // handleRequest processes a single request and sends the response
 // handleRequest 处理单个请求并发送响应
-func (s *StreamRequesterImpl) handleRequest(req *request.Request) {
-	resp := s.RequestHandler.ProcessRequest(req)
+func (s *StreamRequesterImpl) handleRequest(j *job) {
+	resp := s.RequestHandler.ProcessRequestContext(j.ctx, j.req)
 	s.respCh <- resp
 }
 
 // Do submits a request to the stream for processing
 // Do 将请求提交到流中进行处理
 func (s *StreamRequesterImpl) Do(req *request.Request) {
-	s.reqCh <- req
+	s.DoContext(context.Background(), req)
+}
+
+// DoContext submits a request bound to ctx to the stream for processing
+// DoContext 将绑定到 ctx 的请求提交到流中进行处理
+func (s *StreamRequesterImpl) DoContext(ctx context.Context, req *request.Request) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	j := &job{ctx: ctx, req: req}
+	select {
+	case s.reqCh <- j:
+	case <-ctx.Done():
+		// Still deliver a (canceled) response so consumers receive one response per request
+		// 仍然投递一个（已取消的）响应，保证消费者每个请求都能收到一个响应
+		go s.handleRequest(j)
+	}
 }
 
 // ResponseCh returns a channel for receiving responses
@@ -41,12 +66,12 @@ func (s *StreamRequesterImpl) ResponseCh() <-chan *response.Response {
 	return s.respCh
 }
 
-// NewStreamRequester creates a new stream request handler with configurable concurrency
-// NewStreamRequester 创建一个新的流式请求处理器，支持可配置的并发数
-func NewStreamRequester(enableHttp2 bool, concurrency int) StreamRequester {
+// NewStreamRequester creates a new stream request handler with configurable concurrency and options such as core.WithBaseURL
+// NewStreamRequester 创建一个新的流式请求处理器，支持可配置的并发数和 core.WithBaseURL 等配置项
+func NewStreamRequester(enableHttp2 bool, concurrency int, opts ...core.Option) StreamRequester {
 	s := &StreamRequesterImpl{
-		RequestHandler: core.NewRequestHandler(enableHttp2),
-		reqCh:          make(chan *request.Request, concurrency), // Adjustable buffered channel / 可调节的缓冲通道
+		RequestHandler: core.NewRequestHandler(enableHttp2, opts...),
+		reqCh:          make(chan *job, concurrency), // Adjustable buffered channel / 可调节的缓冲通道
 		respCh:         make(chan *response.Response),
 	}
 
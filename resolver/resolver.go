@@ -0,0 +1,188 @@
+// Package resolver provides host name resolution for dialing: a DNS cache, static overrides and custom DNS servers
+// 包 resolver 为拨号提供主机名解析：DNS 缓存、静态覆盖和自定义 DNS 服务器
+package resolver
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// Defaults used by NewCache
+// NewCache 使用的默认值
+const (
+	DefaultTTL = time.Minute // Default time a resolved host is cached / 默认解析结果缓存时间
+	maxEntries = 4096        // Entries kept before expired ones are swept / 清理过期条目前保留的条目数
+)
+
+// Resolver resolves a host name to IP addresses. *net.Resolver implements it.
+// Resolver 将主机名解析为 IP 地址，*net.Resolver 实现了该接口。
+type Resolver interface {
+	// LookupHost returns the addresses of host
+	// LookupHost 返回 host 的地址
+	LookupHost(ctx context.Context, host string) (addrs []string, err error)
+}
+
+// NewServerResolver creates a resolver querying the DNS server at addr ("8.8.8.8" or "10.0.0.2:5353"),
+// port 53 is used when addr has none
+// NewServerResolver 创建一个向 addr（"8.8.8.8" 或 "10.0.0.2:5353"）处的 DNS 服务器查询的解析器，addr 没有端口时使用 53 端口
+func NewServerResolver(addr string) *net.Resolver {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// Cache is a resolver remembering the addresses of hosts for TTL. Concurrent lookups of the same host share
+// one query, so batches of requests to a few hosts do not flood the DNS server.
+// Cache 是一个在 TTL 时间内记住主机地址的解析器。同一主机的并发查询共享一次查询，
+// 因此发往少数主机的批量请求不会大量请求 DNS 服务器。
+type Cache struct {
+	Upstream    Resolver      // Resolver queried on misses, net.DefaultResolver when nil / 未命中时查询的解析器，为 nil 时使用 net.DefaultResolver
+	TTL         time.Duration // Time addresses are cached / 地址的缓存时间
+	NegativeTTL time.Duration // Time lookup failures are cached, 0 does not cache them / 查询失败的缓存时间，0 表示不缓存
+
+	entries map[string]*cacheEntry // Entries keyed by host / 按主机索引的条目
+	mu      sync.Mutex             // Mutex for entries / 条目的互斥锁
+}
+
+// cacheEntry is the cached lookup of a host
+// cacheEntry 是一个主机的缓存查询结果
+type cacheEntry struct {
+	addrs   []string      // Resolved addresses / 解析出的地址
+	err     error         // Lookup error / 查询错误
+	expires time.Time     // Expiry of the entry / 条目的过期时间
+	ready   chan struct{} // Closed once the lookup finished / 查询完成后关闭
+}
+
+// NewCache creates a DNS cache in front of upstream (net.DefaultResolver when nil), DefaultTTL is used when ttl is zero
+// NewCache 创建一个位于 upstream（为 nil 时使用 net.DefaultResolver）之前的 DNS 缓存，ttl 为零时使用 DefaultTTL
+func NewCache(ttl time.Duration, upstream Resolver) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{Upstream: upstream, TTL: ttl}
+}
+
+// LookupHost returns the cached addresses of host, querying the upstream resolver when they are missing or expired
+// LookupHost 返回 host 的缓存地址，缺失或过期时查询上游解析器
+func (c *Cache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	if !ok || (isReady(entry) && !time.Now().Before(entry.expires)) {
+		entry = &cacheEntry{ready: make(chan struct{})}
+		if c.entries == nil {
+			c.entries = make(map[string]*cacheEntry)
+		}
+		if len(c.entries) >= maxEntries {
+			c.sweepLocked()
+		}
+		c.entries[host] = entry
+		// The query outlives the caller's context, as other lookups may be waiting for it
+		// 查询不受调用方上下文的取消影响，因为其他查询可能在等待它
+		go c.resolve(context.WithoutCancel(ctx), host, entry)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.addrs, entry.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resolve queries the upstream resolver for host and completes entry
+// resolve 向上游解析器查询 host 并完成 entry
+func (c *Cache) resolve(ctx context.Context, host string, entry *cacheEntry) {
+	upstream := c.Upstream
+	if upstream == nil {
+		upstream = net.DefaultResolver
+	}
+	addrs, err := upstream.LookupHost(ctx, host)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.addrs, entry.err = addrs, err
+	ttl := c.TTL
+	if err != nil {
+		ttl = c.NegativeTTL
+	}
+	entry.expires = time.Now().Add(ttl)
+	close(entry.ready)
+	if ttl <= 0 && c.entries[host] == entry {
+		delete(c.entries, host)
+	}
+}
+
+// Delete removes the cached addresses of host
+// Delete 删除 host 的缓存地址
+func (c *Cache) Delete(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, host)
+}
+
+// Flush removes every cached address
+// Flush 删除所有缓存地址
+func (c *Cache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = nil
+}
+
+// sweepLocked removes expired entries
+// sweepLocked 删除过期的条目
+func (c *Cache) sweepLocked() {
+	now := time.Now()
+	for host, entry := range c.entries {
+		if isReady(entry) && !now.Before(entry.expires) {
+			delete(c.entries, host)
+		}
+	}
+}
+
+// isReady reports whether the lookup of entry finished
+// isReady 报告 entry 的查询是否已完成
+func isReady(entry *cacheEntry) bool {
+	select {
+	case <-entry.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// Override returns the address overrides maps host:port of addr to, like curl --resolve.
+// Keys are "host:port" or "host" for any port; values are an IP, used with the port of addr, or "ip:port".
+// Override 像 curl --resolve 一样返回 overrides 中 addr 的 host:port 映射到的地址。
+// 键为 "host:port"，或表示任意端口的 "host"；值为 IP（使用 addr 的端口）或 "ip:port"。
+func Override(overrides map[string]string, addr string) (string, bool) {
+	if len(overrides) == 0 {
+		return "", false
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", false
+	}
+	target, ok := overrides[addr]
+	if !ok {
+		if target, ok = overrides[host]; !ok {
+			return "", false
+		}
+	}
+	if _, _, err := net.SplitHostPort(target); err == nil {
+		return target, true
+	}
+	return net.JoinHostPort(target, port), true
+}
@@ -0,0 +1,77 @@
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff computes the wait time between attempts
+// Backoff 计算两次尝试之间的等待时间
+type Backoff interface {
+	// Delay returns the wait time before retry number retry (starting at 1)
+	// Delay 返回第 retry 次重试（从 1 开始）之前的等待时间
+	Delay(retry int) time.Duration
+}
+
+// ConstantBackoff waits the same interval before every retry
+// ConstantBackoff 每次重试前等待相同的时间间隔
+type ConstantBackoff struct {
+	Interval time.Duration // Wait time before each retry / 每次重试前的等待时间
+}
+
+// Delay returns the constant interval
+// Delay 返回固定的时间间隔
+func (b *ConstantBackoff) Delay(int) time.Duration {
+	return b.Interval
+}
+
+// ExponentialBackoff doubles (or multiplies by Multiplier) the wait time after every retry, capped at Max
+// ExponentialBackoff 每次重试后将等待时间翻倍（或乘以 Multiplier），最大不超过 Max
+type ExponentialBackoff struct {
+	Initial    time.Duration // Wait time before the first retry / 第一次重试前的等待时间
+	Max        time.Duration // Upper bound of the wait time, 0 means unbounded / 等待时间上限，0 表示不限制
+	Multiplier float64       // Growth factor, 2 when zero; values below 1 are treated as 1 since the wait never shrinks / 增长系数，为零时为 2；等待时间不会缩短，小于 1 的值按 1 处理
+	Jitter     bool          // Whether to pick a random wait in [0, delay) (full jitter) / 是否在 [0, delay) 中随机选取等待时间（完全抖动）
+}
+
+// Delay returns the exponential wait time for retry
+// Delay 返回第 retry 次重试的指数等待时间
+func (b *ExponentialBackoff) Delay(retry int) time.Duration {
+	multiplier := b.Multiplier
+	switch {
+	case multiplier == 0:
+		multiplier = 2
+	case multiplier < 1:
+		multiplier = 1
+	}
+	delay := float64(b.Initial) * math.Pow(multiplier, float64(retry-1))
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	if delay > math.MaxInt64 {
+		delay = math.MaxInt64
+	}
+	if b.Jitter && delay > 0 {
+		delay = rand.Float64() * delay
+	}
+	return time.Duration(delay)
+}
+
+// NewConstantBackoff creates a backoff that always waits interval
+// NewConstantBackoff 创建一个始终等待 interval 的退避策略
+func NewConstantBackoff(interval time.Duration) *ConstantBackoff {
+	return &ConstantBackoff{Interval: interval}
+}
+
+// NewExponentialBackoff creates a doubling backoff starting at initial and capped at max
+// NewExponentialBackoff 创建一个从 initial 开始翻倍、最大为 max 的退避策略
+func NewExponentialBackoff(initial, max time.Duration) *ExponentialBackoff {
+	return &ExponentialBackoff{Initial: initial, Max: max, Multiplier: 2}
+}
+
+// NewJitterBackoff creates a doubling backoff with full jitter, starting at initial and capped at max
+// NewJitterBackoff 创建一个带完全抖动的翻倍退避策略，从 initial 开始、最大为 max
+func NewJitterBackoff(initial, max time.Duration) *ExponentialBackoff {
+	return &ExponentialBackoff{Initial: initial, Max: max, Multiplier: 2, Jitter: true}
+}
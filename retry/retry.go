@@ -0,0 +1,171 @@
+// Package retry provides retry policies and backoff strategies for HTTP requests
+// 包 retry 提供 HTTP 请求的重试策略和退避策略
+package retry
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/reqerr"
+)
+
+// Condition decides whether an attempt that ended with statusCode and err should be retried
+// Condition 判断以 statusCode 和 err 结束的尝试是否应当重试
+type Condition func(statusCode int, err error) bool
+
+// Policy describes how failed requests are retried
+// Policy 描述失败请求的重试方式
+type Policy struct {
+	MaxAttempts        int           // Maximum number of attempts including the first one / 最大尝试次数（包括第一次）
+	Backoff            Backoff       // Wait strategy between attempts, nil means no wait / 尝试之间的等待策略，nil 表示不等待
+	Conditions         []Condition   // Retry when any condition matches, DefaultCondition when empty / 任一条件满足即重试，为空时使用 DefaultCondition
+	RetryNonIdempotent bool          // Whether DefaultCondition also retries non-idempotent methods such as POST / DefaultCondition 是否也重试 POST 等非幂等方法
+	RespectRetryAfter  bool          // Whether to honor the Retry-After response header / 是否遵循 Retry-After 响应头
+	MaxRetryAfter      time.Duration // Upper bound for Retry-After waits, 0 means unbounded / Retry-After 等待时间上限，0 表示不限制
+}
+
+// NewPolicy creates a retry policy that honors Retry-After
+// NewPolicy 创建一个遵循 Retry-After 的重试策略
+func NewPolicy(maxAttempts int, backoff Backoff, conditions ...Condition) *Policy {
+	return &Policy{
+		MaxAttempts:       maxAttempts,
+		Backoff:           backoff,
+		Conditions:        conditions,
+		RespectRetryAfter: true,
+	}
+}
+
+// ShouldRetry reports whether an attempt of a method request that ended with statusCode and err should be retried.
+// The server may already have acted on a failed non-idempotent request, so DefaultCondition only retries them when
+// RetryNonIdempotent is set; explicit conditions apply to every method.
+// ShouldRetry 报告以 statusCode 和 err 结束的 method 请求尝试是否应当重试。失败的非幂等请求可能已被服务器处理，
+// 因此只有设置 RetryNonIdempotent 时 DefaultCondition 才会重试它们；显式条件适用于所有方法。
+func (p *Policy) ShouldRetry(method string, statusCode int, err error) bool {
+	if len(p.Conditions) == 0 {
+		return (p.RetryNonIdempotent || Idempotent(method)) && DefaultCondition(statusCode, err)
+	}
+	for _, condition := range p.Conditions {
+		if condition(statusCode, err) {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the wait time before retry number retry (starting at 1), honoring Retry-After if enabled
+// Delay 返回第 retry 次重试（从 1 开始）之前的等待时间，启用时遵循 Retry-After
+func (p *Policy) Delay(retry int, header http.Header) time.Duration {
+	if p.RespectRetryAfter {
+		if wait, ok := parseRetryAfter(header.Get("Retry-After")); ok {
+			if p.MaxRetryAfter > 0 && wait > p.MaxRetryAfter {
+				wait = p.MaxRetryAfter
+			}
+			return wait
+		}
+	}
+	if p.Backoff == nil {
+		return 0
+	}
+	return p.Backoff.Delay(retry)
+}
+
+// DefaultCondition retries on retryable errors (see reqerr.IsRetryable) and on 429, 502, 503 and 504 responses
+// DefaultCondition 在可重试错误（见 reqerr.IsRetryable）以及 429、502、503、504 响应时重试
+func DefaultCondition(statusCode int, err error) bool {
+	if err != nil {
+		return reqerr.IsRetryable(err)
+	}
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Idempotent reports whether method is idempotent (GET, HEAD, OPTIONS, TRACE, PUT and DELETE)
+// Idempotent 报告 method 是否幂等（GET、HEAD、OPTIONS、TRACE、PUT 和 DELETE）
+func Idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// RetryOnStatus retries when the response status code is one of codes
+// RetryOnStatus 在响应状态码属于 codes 时重试
+func RetryOnStatus(codes ...int) Condition {
+	return func(statusCode int, err error) bool {
+		if err != nil {
+			return false
+		}
+		for _, code := range codes {
+			if statusCode == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetryOnErrors retries when the error matches any of targets with errors.Is
+// RetryOnErrors 在错误通过 errors.Is 匹配任一 targets 时重试
+func RetryOnErrors(targets ...error) Condition {
+	return func(_ int, err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetryOnKinds retries when the error is classified as one of kinds
+// RetryOnKinds 在错误被归类为 kinds 之一时重试
+func RetryOnKinds(kinds ...reqerr.Kind) Condition {
+	return func(_ int, err error) bool {
+		if err == nil {
+			return false
+		}
+		kind := reqerr.KindOf(err)
+		for _, k := range kinds {
+			if kind == k {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetryOnAnyError retries on every transport error
+// RetryOnAnyError 在任何传输错误时重试
+func RetryOnAnyError() Condition {
+	return func(_ int, err error) bool {
+		return err != nil
+	}
+}
+
+// parseRetryAfter parses a Retry-After value given in seconds or as an HTTP date
+// parseRetryAfter 解析以秒数或 HTTP 日期表示的 Retry-After 值
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
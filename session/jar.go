@@ -0,0 +1,413 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format is a cookie file format
+// Format 是 Cookie 文件格式
+type Format int
+
+const (
+	FormatJSON     Format = iota // JSON array of Cookie / Cookie 的 JSON 数组
+	FormatNetscape               // Netscape cookies.txt format used by curl and browsers / curl 和浏览器使用的 Netscape cookies.txt 格式
+)
+
+// httpOnlyPrefix marks HttpOnly cookies in the Netscape format
+// httpOnlyPrefix 在 Netscape 格式中标记 HttpOnly Cookie
+const httpOnlyPrefix = "#HttpOnly_"
+
+// Cookie is a cookie stored in a Jar
+// Cookie 是存储在 Jar 中的 Cookie
+type Cookie struct {
+	Name     string    `json:"name"`              // Cookie name / Cookie 名称
+	Value    string    `json:"value"`             // Cookie value / Cookie 值
+	Domain   string    `json:"domain"`            // Domain without leading dot / 不带前导点的域名
+	Path     string    `json:"path"`              // Path / 路径
+	Expires  time.Time `json:"expires,omitempty"` // Expiry time, zero for session cookies / 过期时间，会话 Cookie 为零值
+	Secure   bool      `json:"secure"`            // Only sent over HTTPS / 仅通过 HTTPS 发送
+	HttpOnly bool      `json:"httpOnly"`          // Not accessible to scripts / 脚本不可访问
+	HostOnly bool      `json:"hostOnly"`          // Only sent to Domain itself, not to subdomains / 仅发送到 Domain 本身，不发送到子域名
+	created  time.Time // Creation time used to order cookies / 用于排序的创建时间
+}
+
+// Jar is a concurrency-safe cookie jar implementing http.CookieJar that can be saved and loaded.
+// Public suffixes are not checked, so only use it with hosts you trust.
+// Jar 是实现 http.CookieJar 的并发安全 Cookie 容器，可以保存和加载。
+// 不检查公共后缀，请仅用于可信的主机。
+type Jar struct {
+	cookies  map[string]*Cookie // Cookies keyed by domain, path and name / 以域名、路径和名称为键的 Cookie
+	filename string             // Backing file, empty for in-memory jars / 持久化文件，内存容器为空
+	format   Format             // Format of the backing file / 持久化文件的格式
+	mu       sync.Mutex         // Mutex for cookies / Cookie 的互斥锁
+}
+
+// NewJar creates an in-memory cookie jar
+// NewJar 创建一个内存 Cookie 容器
+func NewJar() *Jar {
+	return &Jar{cookies: make(map[string]*Cookie)}
+}
+
+// NewFileJar creates a cookie jar persisted to filename in format, loading the file if it exists.
+// Call Save to write the cookies back.
+// NewFileJar 创建一个以 format 格式持久化到 filename 的 Cookie 容器，文件存在时会加载。调用 Save 写回 Cookie。
+func NewFileJar(filename string, format Format) (*Jar, error) {
+	jar := NewJar()
+	jar.filename, jar.format = filename, format
+	if err := jar.LoadFile(filename, format); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return jar, nil
+}
+
+// SetCookies stores the cookies received in a response from u
+// SetCookies 存储从 u 的响应中收到的 Cookie
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host := canonicalHost(u.Host)
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, c := range cookies {
+		cookie := &Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			created:  now,
+		}
+
+		// Domain attribute must match the host, cookies without it are host-only
+		// Domain 属性必须与主机匹配，没有该属性的 Cookie 仅发送到该主机
+		if domain := strings.ToLower(strings.TrimPrefix(c.Domain, ".")); domain == "" {
+			cookie.Domain, cookie.HostOnly = host, true
+		} else if domainMatch(host, domain) && (net.ParseIP(host) == nil || host == domain) {
+			cookie.Domain = domain
+		} else {
+			continue
+		}
+		if cookie.Path == "" || cookie.Path[0] != '/' {
+			cookie.Path = defaultPath(u.Path)
+		}
+
+		// Max-Age takes precedence over Expires, expired cookies are removed
+		// Max-Age 优先于 Expires，已过期的 Cookie 会被删除
+		switch {
+		case c.MaxAge < 0:
+			cookie.Expires = now.Add(-time.Second)
+		case c.MaxAge > 0:
+			cookie.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		default:
+			cookie.Expires = c.Expires
+		}
+
+		key := cookieKey(cookie)
+		if !cookie.Expires.IsZero() && !cookie.Expires.After(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		if old, ok := j.cookies[key]; ok {
+			cookie.created = old.created
+		}
+		j.cookies[key] = cookie
+	}
+}
+
+// Cookies returns the cookies to send in a request to u
+// Cookies 返回向 u 发送请求时需要携带的 Cookie
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	host := canonicalHost(u.Host)
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+	now := time.Now()
+
+	j.mu.Lock()
+	var selected []*Cookie
+	for key, cookie := range j.cookies {
+		if !cookie.Expires.IsZero() && !cookie.Expires.After(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		if cookie.Secure && !secure {
+			continue
+		}
+		if cookie.HostOnly && host != cookie.Domain || !cookie.HostOnly && !domainMatch(host, cookie.Domain) {
+			continue
+		}
+		if !pathMatch(path, cookie.Path) {
+			continue
+		}
+		selected = append(selected, cookie)
+	}
+	j.mu.Unlock()
+
+	// Longer paths first, then older cookies first (RFC 6265 section 5.4)
+	// 路径较长的优先，其次是较早创建的优先（RFC 6265 第 5.4 节）
+	sort.Slice(selected, func(a, b int) bool {
+		if len(selected[a].Path) != len(selected[b].Path) {
+			return len(selected[a].Path) > len(selected[b].Path)
+		}
+		return selected[a].created.Before(selected[b].created)
+	})
+	cookies := make([]*http.Cookie, 0, len(selected))
+	for _, cookie := range selected {
+		cookies = append(cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return cookies
+}
+
+// All returns a copy of every unexpired cookie in the jar
+// All 返回容器中所有未过期 Cookie 的副本
+func (j *Jar) All() []Cookie {
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	cookies := make([]Cookie, 0, len(j.cookies))
+	for _, cookie := range j.cookies {
+		if cookie.Expires.IsZero() || cookie.Expires.After(now) {
+			cookies = append(cookies, *cookie)
+		}
+	}
+	sort.Slice(cookies, func(a, b int) bool {
+		return cookieKey(&cookies[a]) < cookieKey(&cookies[b])
+	})
+	return cookies
+}
+
+// Clear removes every cookie from the jar
+// Clear 删除容器中的所有 Cookie
+func (j *Jar) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.cookies = make(map[string]*Cookie)
+}
+
+// Save writes the cookies to the jar's file, it fails for in-memory jars
+// Save 将 Cookie 写入容器的持久化文件，内存容器会返回错误
+func (j *Jar) Save() error {
+	if j.filename == "" {
+		return fmt.Errorf("cookie jar has no file")
+	}
+	return j.SaveFile(j.filename, j.format)
+}
+
+// SaveFile atomically writes the cookies to filename in format
+// SaveFile 以 format 格式原子地将 Cookie 写入 filename
+func (j *Jar) SaveFile(filename string, format Format) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create cookie file error: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = j.Write(tmp, format); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("write cookie file error: %w", err)
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// LoadFile adds the cookies stored in filename in format to the jar
+// LoadFile 将 filename 中以 format 格式存储的 Cookie 加入容器
+func (j *Jar) LoadFile(filename string, format Format) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return j.Read(file, format)
+}
+
+// Write writes the unexpired cookies to w in format
+// Write 以 format 格式将未过期的 Cookie 写入 w
+func (j *Jar) Write(w io.Writer, format Format) error {
+	cookies := j.All()
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(cookies); err != nil {
+			return fmt.Errorf("encode cookies error: %w", err)
+		}
+		return nil
+	case FormatNetscape:
+		buf := bufio.NewWriter(w)
+		_, _ = buf.WriteString("# Netscape HTTP Cookie File\n")
+		for _, cookie := range cookies {
+			domain := cookie.Domain
+			if !cookie.HostOnly {
+				domain = "." + domain
+			}
+			if cookie.HttpOnly {
+				domain = httpOnlyPrefix + domain
+			}
+			var expires int64
+			if !cookie.Expires.IsZero() {
+				expires = cookie.Expires.Unix()
+			}
+			_, _ = fmt.Fprintf(buf, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", domain, netscapeBool(!cookie.HostOnly),
+				cookie.Path, netscapeBool(cookie.Secure), expires, cookie.Name, cookie.Value)
+		}
+		if err := buf.Flush(); err != nil {
+			return fmt.Errorf("write cookies error: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported cookie format: %d", format)
+}
+
+// Read adds the cookies read from r in format to the jar, expired cookies are skipped
+// Read 将从 r 中以 format 格式读取的 Cookie 加入容器，已过期的 Cookie 会被跳过
+func (j *Jar) Read(r io.Reader, format Format) error {
+	var cookies []Cookie
+	switch format {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&cookies); err != nil {
+			return fmt.Errorf("decode cookies error: %w", err)
+		}
+	case FormatNetscape:
+		var err error
+		if cookies, err = readNetscape(r); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported cookie format: %d", format)
+	}
+
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for i := range cookies {
+		cookie := cookies[i]
+		if cookie.Name == "" || cookie.Domain == "" || !cookie.Expires.IsZero() && !cookie.Expires.After(now) {
+			continue
+		}
+		cookie.Domain = strings.ToLower(strings.TrimPrefix(cookie.Domain, "."))
+		if cookie.Path == "" {
+			cookie.Path = "/"
+		}
+		cookie.created = now
+		j.cookies[cookieKey(&cookie)] = &cookie
+	}
+	return nil
+}
+
+// readNetscape parses cookies in the Netscape cookies.txt format
+// readNetscape 解析 Netscape cookies.txt 格式的 Cookie
+func readNetscape(r io.Reader) ([]Cookie, error) {
+	var cookies []Cookie
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := strings.HasPrefix(text, httpOnlyPrefix)
+		text = strings.TrimPrefix(text, httpOnlyPrefix)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid cookie line %d: expected 7 fields, got %d", line, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie line %d: %w", line, err)
+		}
+		cookie := Cookie{
+			Domain:   fields[0],
+			HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read cookies error: %w", err)
+	}
+	return cookies, nil
+}
+
+// netscapeBool formats a boolean field of the Netscape format
+// netscapeBool 格式化 Netscape 格式中的布尔字段
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// cookieKey returns the key identifying a cookie in the jar
+// cookieKey 返回在容器中标识 Cookie 的键
+func cookieKey(cookie *Cookie) string {
+	return cookie.Domain + ";" + cookie.Path + ";" + cookie.Name
+}
+
+// canonicalHost returns the lower-case host without port
+// canonicalHost 返回不带端口的小写主机名
+func canonicalHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
+
+// domainMatch reports whether host is domain or one of its subdomains
+// domainMatch 报告 host 是否为 domain 或其子域名
+func domainMatch(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain) && net.ParseIP(host) == nil
+}
+
+// pathMatch reports whether the request path is within the cookie path
+// pathMatch 报告请求路径是否位于 Cookie 路径之内
+func pathMatch(path, cookiePath string) bool {
+	if path == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(path, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || path[len(cookiePath)] == '/'
+}
+
+// defaultPath returns the default cookie path for a request path (RFC 6265 section 5.1.4)
+// defaultPath 返回请求路径对应的默认 Cookie 路径（RFC 6265 第 5.1.4 节）
+func defaultPath(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return path[:i]
+}
@@ -0,0 +1,43 @@
+// Package session provides persistent sessions that share cookies, default headers and a base URL across requests
+// 包 session 提供在多个请求之间共享 Cookie、默认请求头和基础地址的持久会话
+package session
+
+import (
+	"github.com/GoEnthusiast/httpreq/core"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+)
+
+// Session is a single requester that keeps cookies in a jar. The base URL and default headers
+// set with SetBaseURL and SetHeader apply to every request of the session.
+// Session 是将 Cookie 保存在容器中的单次请求器，通过 SetBaseURL 和 SetHeader 设置的基础地址与默认请求头会应用于会话的每个请求。
+type Session struct {
+	reqsingle.SingleRequester      // Underlying requester / 底层请求器
+	jar                       *Jar // Cookie jar / Cookie 容器
+}
+
+// NewSession creates a session with optional HTTP/2 support and options such as core.WithBaseURL,
+// a nil jar creates an in-memory jar
+// NewSession 创建一个会话，支持可选的 HTTP/2 和 core.WithBaseURL 等配置项，jar 为 nil 时创建内存 Cookie 容器
+func NewSession(enableHttp2 bool, jar *Jar, opts ...core.Option) *Session {
+	if jar == nil {
+		jar = NewJar()
+	}
+	requester := reqsingle.NewSingleRequester(enableHttp2, opts...)
+	requester.SetCookieJar(jar)
+	return &Session{
+		SingleRequester: requester,
+		jar:             jar,
+	}
+}
+
+// Jar returns the session's cookie jar
+// Jar 返回会话的 Cookie 容器
+func (s *Session) Jar() *Jar {
+	return s.jar
+}
+
+// Save writes the session's cookies to the jar's file
+// Save 将会话的 Cookie 写入容器的持久化文件
+func (s *Session) Save() error {
+	return s.jar.Save()
+}
@@ -0,0 +1,26 @@
+// Package signer provides request signers that see the final method, URL, headers and body of a request
+// 包 signer 提供请求签名器，签名时可以看到请求最终的方法、地址、请求头和请求体
+package signer
+
+import (
+	"net/http"
+)
+
+// Signer signs an outgoing request. It runs for every attempt after the headers, authentication and
+// before-request hooks have been applied, so it sees the request exactly as it is sent.
+// Signer 对发出的请求进行签名。它在每次尝试中请求头、认证和请求前钩子生效之后执行，因此看到的就是实际发送的请求。
+type Signer interface {
+	// Sign adds a signature to req, body holds the request body bytes (nil without body)
+	// Sign 为 req 添加签名，body 为请求体字节（无请求体时为 nil）
+	Sign(req *http.Request, body []byte) error
+}
+
+// Func adapts a function to the Signer interface
+// Func 将函数适配为 Signer 接口
+type Func func(req *http.Request, body []byte) error
+
+// Sign calls f(req, body)
+// Sign 调用 f(req, body)
+func (f Func) Sign(req *http.Request, body []byte) error {
+	return f(req, body)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/GoEnthusiast/httpreq/auth"
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// md5Hex 计算 MD5 十六进制摘要
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// newDigestServer 创建一个要求 HTTP Digest 认证的测试服务器，返回服务器和收到的请求数
+func newDigestServer(username, password string) (*httptest.Server, *int32) {
+	var count int32
+	const realm, nonce = "test", "abc123"
+	paramRe := regexp.MustCompile(`(\w+)="?([^",]*)"?`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		header := r.Header.Get("Authorization")
+		if strings.HasPrefix(header, "Digest ") {
+			params := map[string]string{}
+			for _, m := range paramRe.FindAllStringSubmatch(header[7:], -1) {
+				params[m[1]] = m[2]
+			}
+			ha1 := md5Hex(username + ":" + realm + ":" + password)
+			ha2 := md5Hex(r.Method + ":" + params["uri"])
+			want := md5Hex(ha1 + ":" + nonce + ":" + params["nc"] + ":" + params["cnonce"] + ":auth:" + ha2)
+			if params["response"] == want && params["uri"] == r.URL.RequestURI() {
+				_, _ = w.Write([]byte("ok"))
+				return
+			}
+		}
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="%s", nonce="%s", qop="auth", algorithm=MD5`, realm, nonce))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	return server, &count
+}
+
+// TestSingleBasicAndBearerAuth 请求器级别和请求级别的 Basic、Bearer 认证
+func TestSingleBasicAndBearerAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetAuth(auth.Basic("user", "pass"))
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL})
+	if string(resp.ResponseBody) != "Basic dXNlcjpwYXNz" {
+		t.Errorf("Basic 认证错误: %s", resp.ResponseBody)
+	}
+
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL, Auth: auth.Bearer("token")})
+	if string(resp.ResponseBody) != "Bearer token" {
+		t.Errorf("Bearer 认证错误: %s", resp.ResponseBody)
+	}
+}
+
+// TestSingleDigestAuth Digest 认证通过一次 401 质询往返完成，之后复用 nonce
+func TestSingleDigestAuth(t *testing.T) {
+	server, count := newDigestServer("user", "secret")
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetAuth(auth.Digest("user", "secret"))
+
+	resp := requester.Do(&request.Request{
+		Method:      method.POST,
+		URL:         server.URL + "/data?id=1",
+		ContentType: method.ContentTypeText,
+		Body:        "payload",
+	})
+	if resp.Error != nil || resp.ResponseStatusCode != http.StatusOK {
+		t.Fatalf("Digest 认证失败: %d %v", resp.ResponseStatusCode, resp.Error)
+	}
+	if n := atomic.LoadInt32(count); n != 2 {
+		t.Errorf("期望 2 次请求（质询 + 认证）, 实际: %d", n)
+	}
+
+	// 复用已知的质询
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL + "/data"})
+	if resp.ResponseStatusCode != http.StatusOK || atomic.LoadInt32(count) != 3 {
+		t.Errorf("期望直接认证成功: %d, 请求数 %d", resp.ResponseStatusCode, atomic.LoadInt32(count))
+	}
+
+	// 错误的密码只重试一次
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL + "/data", Auth: auth.Digest("user", "wrong")})
+	if resp.ResponseStatusCode != http.StatusUnauthorized {
+		t.Errorf("期望 401, 实际: %d", resp.ResponseStatusCode)
+	}
+}
+
+// TestBatchOAuth2ClientCredentials 客户端凭证令牌被缓存共享，401 时刷新令牌并重试一次
+func TestBatchOAuth2ClientCredentials(t *testing.T) {
+	var issued int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if r.FormValue("grant_type") != "client_credentials" || id != "client" || secret != "secret" || r.FormValue("scope") != "read write" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		n := atomic.AddInt32(&issued, 1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer tokenServer.Close()
+
+	// token-1 被服务器吊销
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token-1" || r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer apiServer.Close()
+
+	// 令牌请求通过请求器的传输层发送，解析覆盖同样生效
+	batchRequester := reqbatch.NewBatchRequester(false)
+	batchRequester.SetResolveOverrides(map[string]string{"auth.test": "127.0.0.1"})
+	batchRequester.SetAuth(auth.ClientCredentials(auth.OAuth2Config{
+		TokenURL:     strings.Replace(tokenServer.URL, "127.0.0.1", "auth.test", 1),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	}))
+
+	resp := batchRequester.Do([]*request.Request{{Method: method.GET, URL: apiServer.URL}})[0]
+	if string(resp.ResponseBody) != "Bearer token-2" {
+		t.Fatalf("期望刷新后的令牌, 实际: %d %s %v", resp.ResponseStatusCode, resp.ResponseBody, resp.Error)
+	}
+
+	requests := []*request.Request{}
+	for i := 0; i < 5; i++ {
+		requests = append(requests, &request.Request{Method: method.GET, URL: apiServer.URL})
+	}
+	for _, resp := range batchRequester.Do(requests) {
+		if string(resp.ResponseBody) != "Bearer token-2" {
+			t.Errorf("期望复用缓存的令牌, 实际: %s", resp.ResponseBody)
+		}
+	}
+	if n := atomic.LoadInt32(&issued); n != 2 {
+		t.Errorf("期望签发 2 个令牌, 实际: %d", n)
+	}
+
+	// 令牌端点拒绝时返回认证错误
+	resp = batchRequester.Do([]*request.Request{{
+		Method: method.GET,
+		URL:    apiServer.URL,
+		Auth:   auth.ClientCredentials(auth.OAuth2Config{TokenURL: tokenServer.URL, ClientID: "bad"}),
+	}})[0]
+	if reqerr.KindOf(resp.Error) != reqerr.KindAuth {
+		t.Errorf("期望认证错误, 实际: %v", resp.Error)
+	}
+}
+
+// TestSingleOAuth2RefreshToken 使用刷新令牌获取访问令牌，并保存服务器轮换的刷新令牌
+func TestSingleOAuth2RefreshToken(t *testing.T) {
+	var refreshTokens []string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshTokens = append(refreshTokens, r.FormValue("refresh_token"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  fmt.Sprintf("access-%d", len(refreshTokens)),
+			"refresh_token": fmt.Sprintf("refresh-%d", len(refreshTokens)),
+			"expires_in":    1, // 小于默认提前刷新时间，每次都会刷新
+		})
+	}))
+	defer tokenServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer apiServer.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetAuth(auth.RefreshToken(auth.OAuth2Config{TokenURL: tokenServer.URL}, "refresh-0"))
+	for i := 1; i <= 2; i++ {
+		resp := requester.Do(&request.Request{Method: method.GET, URL: apiServer.URL})
+		if want := fmt.Sprintf("Bearer access-%d", i); string(resp.ResponseBody) != want {
+			t.Errorf("期望 %s, 实际 %s", want, resp.ResponseBody)
+		}
+	}
+	if strings.Join(refreshTokens, ",") != "refresh-0,refresh-1" {
+		t.Errorf("刷新令牌未轮换: %v", refreshTokens)
+	}
+}
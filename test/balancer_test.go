@@ -0,0 +1,170 @@
+package main
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/balancer"
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/retry"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// TestSingleBalancerRoundRobin 相对地址轮流发送到各个端点，响应中记录所选端点，绝对地址不经过负载均衡
+func TestSingleBalancerRoundRobin(t *testing.T) {
+	var okA, okB atomic.Int32
+	serverA, serverB := newNamedServer("a", &okA), newNamedServer("b", &okB)
+	defer serverA.Close()
+	defer serverB.Close()
+
+	lb, err := balancer.New(balancer.Config{Endpoints: balancer.Endpoints(serverA.URL+"/api", serverB.URL+"/api")})
+	if err != nil {
+		t.Fatalf("创建负载均衡器失败: %v", err)
+	}
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetLoadBalancer(lb)
+
+	var bodies []string
+	for i := 0; i < 4; i++ {
+		resp := requester.Do(&request.Request{Method: method.GET, URL: "/items/{id}", PathParams: map[string]string{"id": "7"}})
+		if resp.Error != nil {
+			t.Fatalf("请求错误: %v", resp.Error)
+		}
+		if want := map[byte]string{'a': serverA.URL + "/api", 'b': serverB.URL + "/api"}[resp.ResponseBody[0]]; resp.Endpoint != want {
+			t.Errorf("响应记录的端点错误: %s %s", resp.Endpoint, resp.ResponseBody)
+		}
+		bodies = append(bodies, string(resp.ResponseBody))
+	}
+	hostA, hostB := serverA.Listener.Addr().String(), serverB.Listener.Addr().String()
+	if bodies[0] != namedBody("a", hostA, "/api/items/7") || bodies[1] != namedBody("b", hostB, "/api/items/7") || bodies[2] != bodies[0] || bodies[3] != bodies[1] {
+		t.Errorf("期望轮流发送到两个端点, 实际: %v", bodies)
+	}
+
+	resp := requester.Do(&request.Request{Method: method.GET, URL: serverB.URL + "/direct"})
+	if resp.Error != nil || string(resp.ResponseBody) != namedBody("b", hostB, "/direct") || resp.Endpoint != "" {
+		t.Errorf("绝对地址不应经过负载均衡: %v %s %s", resp.Error, resp.ResponseBody, resp.Endpoint)
+	}
+
+	if _, err := balancer.New(balancer.Config{Endpoints: balancer.Endpoints("/relative")}); err == nil {
+		t.Error("相对的端点地址应返回错误")
+	}
+}
+
+// TestSingleBalancerEjection 连续失败的端点被摘除，重试转到健康端点，冷却时间后恢复
+func TestSingleBalancerEjection(t *testing.T) {
+	var statusA, statusB atomic.Int32
+	statusA.Store(http.StatusServiceUnavailable)
+	serverA, serverB := newNamedServer("a", &statusA), newNamedServer("b", &statusB)
+	defer serverA.Close()
+	defer serverB.Close()
+
+	lb, err := balancer.New(balancer.Config{
+		Endpoints: balancer.Endpoints(serverA.URL, serverB.URL),
+		MaxFails:  1,
+		Cooldown:  200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("创建负载均衡器失败: %v", err)
+	}
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetLoadBalancer(lb)
+	requester.SetRetryPolicy(&retry.Policy{MaxAttempts: 2})
+
+	hostA, hostB := serverA.Listener.Addr().String(), serverB.Listener.Addr().String()
+	resp := requester.Do(&request.Request{Method: method.GET, URL: "/x"})
+	if resp.Error != nil || string(resp.ResponseBody) != namedBody("b", hostB, "/x") || len(resp.Attempts) != 2 {
+		t.Fatalf("期望重试转到健康端点: %v %s %d", resp.Error, resp.ResponseBody, len(resp.Attempts))
+	}
+	if status := lb.Status(); !status[0].Ejected || status[1].Ejected || status[0].InFlight != 0 {
+		t.Errorf("期望端点 a 被摘除: %+v", status)
+	}
+	for i := 0; i < 3; i++ {
+		if resp := requester.Do(&request.Request{Method: method.GET, URL: "/y"}); string(resp.ResponseBody) != namedBody("b", hostB, "/y") {
+			t.Errorf("摘除期间不应选择端点 a: %s", resp.ResponseBody)
+		}
+	}
+
+	statusA.Store(0)
+	time.Sleep(250 * time.Millisecond)
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		seen[string(requester.Do(&request.Request{Method: method.GET, URL: "/z"}).ResponseBody)] = true
+	}
+	if !seen[namedBody("a", hostA, "/z")] || !seen[namedBody("b", hostB, "/z")] {
+		t.Errorf("冷却时间后端点 a 应恢复: %v", seen)
+	}
+}
+
+// TestBatchBalancerStrategies 加权、最少进行中请求和一致性哈希策略
+func TestBatchBalancerStrategies(t *testing.T) {
+	var okA, okB, okC atomic.Int32
+	serverA, serverB, serverC := newNamedServer("a", &okA), newNamedServer("b", &okB), newNamedServer("c", &okC)
+	defer serverA.Close()
+	defer serverB.Close()
+	defer serverC.Close()
+
+	// 加权轮询按权重比例分配请求
+	weighted, err := balancer.New(balancer.Config{
+		Endpoints: []balancer.Endpoint{{URL: serverA.URL, Weight: 3}, {URL: serverB.URL, Weight: 1}},
+		Strategy:  balancer.Weighted,
+	})
+	if err != nil {
+		t.Fatalf("创建负载均衡器失败: %v", err)
+	}
+	batchRequester := reqbatch.NewBatchRequester(false)
+	batchRequester.SetLoadBalancer(weighted)
+	counts := map[byte]int{}
+	requests := make([]*request.Request, 8)
+	for i := range requests {
+		requests[i] = &request.Request{Method: method.GET, URL: "/w"}
+	}
+	for _, resp := range batchRequester.Do(requests) {
+		if resp.Error != nil {
+			t.Fatalf("请求错误: %v", resp.Error)
+		}
+		counts[resp.ResponseBody[0]]++
+	}
+	if counts['a'] != 6 || counts['b'] != 2 {
+		t.Errorf("期望按 3:1 分配, 实际: %v", counts)
+	}
+
+	// 最少进行中请求选择空闲的端点
+	least, _ := balancer.New(balancer.Config{Endpoints: balancer.Endpoints(serverA.URL, serverB.URL), Strategy: balancer.LeastInFlight})
+	first, doneFirst := least.Pick(nil)
+	second, doneSecond := least.Pick(nil)
+	if first == second {
+		t.Errorf("期望选择进行中请求更少的端点: %s %s", first, second)
+	}
+	doneSecond(http.StatusOK, nil)
+	if third, doneThird := least.Pick(nil); third != second {
+		t.Errorf("期望选择空闲的端点 %s, 实际: %s", second, third)
+	} else {
+		doneThird(http.StatusOK, nil)
+	}
+	doneFirst(http.StatusOK, nil)
+
+	// 一致性哈希：相同的键总是发送到同一端点
+	hashed, _ := balancer.New(balancer.Config{
+		Endpoints: balancer.Endpoints(serverA.URL, serverB.URL, serverC.URL),
+		Strategy:  balancer.ConsistentHash,
+		Key:       func(req *request.Request) string { return req.Meta["user"].(string) },
+	})
+	batchRequester.SetLoadBalancer(hashed)
+	users := []string{"alice", "bob", "carol", "dave", "erin", "frank"}
+	requests = nil
+	for _, user := range append(users, users...) {
+		requests = append(requests, &request.Request{Method: method.GET, URL: "/h", Meta: map[string]interface{}{"user": user}})
+	}
+	endpoints := map[string]string{}
+	for _, resp := range batchRequester.Do(requests) {
+		user := resp.Request.Meta["user"].(string)
+		if previous, ok := endpoints[user]; ok && previous != resp.Endpoint {
+			t.Errorf("相同的键应发送到同一端点: %s %s %s", user, previous, resp.Endpoint)
+		}
+		endpoints[user] = resp.Endpoint
+	}
+}
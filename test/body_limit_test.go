@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/GoEnthusiast/httpreq/core"
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// TestSingleMaxBodySize 响应体超过上限时中止读取并返回 ErrBodyTooLarge
+func TestSingleMaxBodySize(t *testing.T) {
+	server := newDownloadServer(1 << 20)
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetMaxBodySize(1024)
+
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL})
+	if !errors.Is(resp.Error, core.ErrBodyTooLarge) || resp.ResponseBody != nil {
+		t.Fatalf("期望 ErrBodyTooLarge 且不保留响应体, 实际: %v, %d", resp.Error, len(resp.ResponseBody))
+	}
+
+	// 保留截断的前缀
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL, KeepTruncatedBody: true})
+	if !errors.Is(resp.Error, core.ErrBodyTooLarge) || len(resp.ResponseBody) != 1024 {
+		t.Fatalf("期望保留 1024 字节前缀, 实际: %v, %d", resp.Error, len(resp.ResponseBody))
+	}
+
+	// 请求级别的上限覆盖默认值，负数表示不限制
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL, MaxBodySize: -1})
+	if resp.Error != nil || len(resp.ResponseBody) != 1<<20 {
+		t.Fatalf("期望完整读取, 实际: %v, %d", resp.Error, len(resp.ResponseBody))
+	}
+
+	// 恰好等于上限的响应体不算超限
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL, MaxBodySize: 1 << 20})
+	if resp.Error != nil {
+		t.Fatalf("请求错误: %v", resp.Error)
+	}
+
+	// 流式模式同样生效
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL, StreamBody: true})
+	if resp.Error != nil {
+		t.Fatalf("请求错误: %v", resp.Error)
+	}
+	_, err := io.Copy(io.Discard, resp.BodyReader)
+	_ = resp.BodyReader.Close()
+	if !errors.Is(err, core.ErrBodyTooLarge) || !errors.Is(resp.Error, core.ErrBodyTooLarge) {
+		t.Fatalf("期望流式读取超限, 实际: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/breaker"
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/reqstream"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// TestStreamCircuitBreaker 上游故障时熔断器打开并快速失败，打开时长结束后由探测请求恢复
+func TestStreamCircuitBreaker(t *testing.T) {
+	var (
+		hits    atomic.Int32
+		healthy atomic.Bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	var (
+		mu          sync.Mutex
+		transitions []string
+	)
+	b := breaker.New(breaker.Config{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		OpenDuration: 200 * time.Millisecond,
+		OnStateChange: func(h string, from, to breaker.State) {
+			mu.Lock()
+			defer mu.Unlock()
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	})
+	streamRequester := reqstream.NewStreamRequester(false, 1)
+	streamRequester.SetCircuitBreaker(b)
+
+	// 上游故障：前 4 个请求失败后熔断器打开，其余请求不再发送
+	go func() {
+		for i := 0; i < 10; i++ {
+			streamRequester.Do(&request.Request{Method: method.GET, URL: server.URL})
+		}
+	}()
+	var open int
+	for i := 0; i < 10; i++ {
+		resp := <-streamRequester.ResponseCh()
+		if errors.Is(resp.Error, reqerr.ErrCircuitOpen) {
+			open++
+		}
+	}
+	if hits.Load() != 4 || open != 6 {
+		t.Errorf("期望发送 4 个请求、快速失败 6 个, 实际: 发送 %d 快速失败 %d", hits.Load(), open)
+	}
+	if status := b.States()[host]; status.State != breaker.StateOpen {
+		t.Errorf("期望熔断器打开, 实际: %+v", status)
+	}
+
+	// 打开时长结束后，探测请求成功则熔断器关闭
+	healthy.Store(true)
+	time.Sleep(250 * time.Millisecond)
+	if state := b.State(host); state != breaker.StateHalfOpen {
+		t.Errorf("期望熔断器半开, 实际: %v", state)
+	}
+	streamRequester.Do(&request.Request{Method: method.GET, URL: server.URL})
+	if resp := <-streamRequester.ResponseCh(); resp.Error != nil || resp.ResponseStatusCode != http.StatusOK {
+		t.Fatalf("探测请求失败: %d %v", resp.ResponseStatusCode, resp.Error)
+	}
+	if state := b.State(host); state != breaker.StateClosed {
+		t.Errorf("期望熔断器关闭, 实际: %v", state)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(transitions, ",") != "closed->open,open->half-open,half-open->closed" {
+		t.Errorf("状态变化错误: %v", transitions)
+	}
+}
+
+// TestCircuitBreakerHalfOpenFailure 半开状态下探测失败会重新打开熔断器，且同时只允许配置数量的探测
+func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
+	b := breaker.New(breaker.Config{MinRequests: 1, OpenDuration: 50 * time.Millisecond})
+	done, err := b.Allow("example.com")
+	if err != nil {
+		t.Fatalf("关闭状态应允许请求: %v", err)
+	}
+	done(http.StatusBadGateway, nil)
+	if _, err := b.Allow("example.com"); !errors.Is(err, reqerr.ErrCircuitOpen) {
+		t.Fatalf("期望熔断器打开, 实际: %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	probe, err := b.Allow("example.com")
+	if err != nil {
+		t.Fatalf("半开状态应允许探测: %v", err)
+	}
+	if _, err := b.Allow("example.com"); !errors.Is(err, reqerr.ErrCircuitOpen) {
+		t.Errorf("探测进行中应拒绝其他请求, 实际: %v", err)
+	}
+	probe(0, reqerr.New(reqerr.KindConnect, "dial error", nil))
+	if state := b.State("example.com"); state != breaker.StateOpen {
+		t.Errorf("探测失败后期望熔断器打开, 实际: %v", state)
+	}
+
+	// 其他主机不受影响
+	if _, err := b.Allow("other.example.com"); err != nil {
+		t.Errorf("其他主机不应被熔断: %v", err)
+	}
+}
+
+// TestCircuitBreakerSweep 熔断器数量达到上限时清理计数窗口已结束的空闲主机，打开的熔断器和有进行中请求的熔断器被保留
+func TestCircuitBreakerSweep(t *testing.T) {
+	b := breaker.New(breaker.Config{MinRequests: 1, Window: 20 * time.Millisecond})
+	down, _ := b.Allow("down.example.com")
+	down(http.StatusBadGateway, nil)
+	if _, err := b.Allow("busy.example.com"); err != nil {
+		t.Fatalf("关闭状态应允许请求: %v", err)
+	}
+	for i := 0; i < 4094; i++ {
+		done, err := b.Allow("host" + strconv.Itoa(i) + ".example.com")
+		if err != nil {
+			t.Fatalf("关闭状态应允许请求: %v", err)
+		}
+		done(http.StatusOK, nil)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, err := b.Allow("new.example.com"); err != nil {
+		t.Fatalf("关闭状态应允许请求: %v", err)
+	}
+	states := b.States()
+	if len(states) != 3 || states["down.example.com"].State != breaker.StateOpen {
+		t.Errorf("期望只保留打开的、进行中的和新的熔断器, 实际 %d 个: %v", len(states), states["down.example.com"])
+	}
+}
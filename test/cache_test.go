@@ -0,0 +1,194 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/auth"
+	"github.com/GoEnthusiast/httpreq/cache"
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// TestSingleCacheFreshness 新鲜的响应直接由缓存提供，no-store 和 Vary 不同的请求不使用缓存，POST 使缓存失效
+func TestSingleCacheFreshness(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+			_, _ = w.Write([]byte(r.Header.Get("Accept-Language")))
+			return
+		default:
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		_, _ = w.Write([]byte("catalogue"))
+	}))
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetCache(cache.New(cache.NewMemoryStore(100, 0)))
+	get := func(path string, header http.Header) (cache.Status, string) {
+		resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL + path, Header: header})
+		if resp.Error != nil || resp.ResponseStatusCode != http.StatusOK {
+			t.Fatalf("请求错误: %d %v", resp.ResponseStatusCode, resp.Error)
+		}
+		return resp.CacheStatus, string(resp.ResponseBody)
+	}
+
+	if status, _ := get("/items", nil); status != cache.StatusMiss {
+		t.Errorf("第一次请求期望未命中, 实际: %v", status)
+	}
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL + "/items"})
+	if resp.CacheStatus != cache.StatusHit || string(resp.ResponseBody) != "catalogue" || resp.Header.Get("Age") == "" {
+		t.Errorf("第二次请求期望命中缓存: %v %s %v", resp.CacheStatus, resp.ResponseBody, resp.Header)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("命中缓存时不应发送请求, 服务器收到 %d 个请求", hits.Load())
+	}
+
+	// 请求要求不使用缓存
+	if status, _ := get("/items", http.Header{"Cache-Control": {"no-cache"}}); status != cache.StatusMiss {
+		t.Errorf("no-cache 请求期望未命中, 实际: %v", status)
+	}
+
+	// no-store 响应不会被存储
+	get("/nostore", nil)
+	if status, _ := get("/nostore", nil); status != cache.StatusMiss {
+		t.Errorf("no-store 响应不应被缓存, 实际: %v", status)
+	}
+
+	// Vary 指定的请求头不同时不使用缓存
+	get("/vary", http.Header{"Accept-Language": {"en"}})
+	if status, body := get("/vary", http.Header{"Accept-Language": {"en"}}); status != cache.StatusHit || body != "en" {
+		t.Errorf("相同 Accept-Language 期望命中: %v %s", status, body)
+	}
+	if status, body := get("/vary", http.Header{"Accept-Language": {"zh"}}); status != cache.StatusMiss || body != "zh" {
+		t.Errorf("不同 Accept-Language 期望未命中: %v %s", status, body)
+	}
+
+	// POST 成功后缓存失效
+	requester.Do(&request.Request{Method: method.POST, URL: server.URL + "/items", ContentType: method.ContentTypeText, Body: "x"})
+	if status, _ := get("/items", nil); status != cache.StatusMiss {
+		t.Errorf("POST 之后期望缓存失效, 实际: %v", status)
+	}
+}
+
+// TestSingleCacheSkipsAuth 使用认证提供者的请求不经过缓存，不同用户的响应不会互相提供
+func TestSingleCacheSkipsAuth(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		user, _, _ := r.BasicAuth()
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		_, _ = w.Write([]byte("profile of " + user))
+	}))
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetCache(cache.New(cache.NewMemoryStore(100, 0)))
+	for _, user := range []string{"alice", "bob"} {
+		resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL + "/me", Auth: auth.Basic(user, "pass")})
+		if resp.Error != nil || resp.CacheStatus != cache.StatusNone || string(resp.ResponseBody) != "profile of "+user {
+			t.Errorf("%s 的请求期望不经过缓存: %v %s %v", user, resp.CacheStatus, resp.ResponseBody, resp.Error)
+		}
+	}
+	if hits.Load() != 2 {
+		t.Errorf("期望服务器收到 2 个请求, 实际: %d", hits.Load())
+	}
+}
+
+// TestBatchCacheRevalidate 过期的响应使用 ETag 和 Last-Modified 重新验证，磁盘存储可以在请求器之间共享
+func TestBatchCacheRevalidate(t *testing.T) {
+	lastModified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	var full, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/modified":
+			w.Header().Set("Cache-Control", "max-age=0")
+			w.Header().Set("Last-Modified", lastModified)
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		full.Add(1)
+		_, _ = w.Write([]byte("body of " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	store, err := cache.NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("创建磁盘存储失败: %v", err)
+	}
+	requests := func() []*request.Request {
+		return []*request.Request{
+			{Method: method.GET, URL: server.URL + "/etag"},
+			{Method: method.GET, URL: server.URL + "/modified"},
+		}
+	}
+
+	first := reqbatch.NewBatchRequester(false)
+	first.SetCache(cache.New(store))
+	for _, resp := range first.Do(requests()) {
+		if resp.Error != nil || resp.CacheStatus != cache.StatusMiss {
+			t.Fatalf("第一次请求期望未命中: %v %v", resp.CacheStatus, resp.Error)
+		}
+	}
+
+	// 新的请求器使用同一个磁盘目录，条件请求得到 304 后返回已存储的响应
+	second := reqbatch.NewBatchRequester(false)
+	second.SetCache(cache.New(store))
+	for _, resp := range second.Do(requests()) {
+		want := "body of " + resp.Request.URL[len(server.URL):]
+		if resp.Error != nil || resp.CacheStatus != cache.StatusRevalidated || resp.ResponseStatusCode != http.StatusOK || string(resp.ResponseBody) != want {
+			t.Errorf("期望重新验证: %v %d %s %v", resp.CacheStatus, resp.ResponseStatusCode, resp.ResponseBody, resp.Error)
+		}
+	}
+	if full.Load() != 2 || notModified.Load() != 2 {
+		t.Errorf("期望 2 个完整响应和 2 个 304, 实际: %d %d", full.Load(), notModified.Load())
+	}
+}
+
+// TestMemoryStoreEviction 内存存储按最近最少使用的顺序淘汰条目
+func TestMemoryStoreEviction(t *testing.T) {
+	store := cache.NewMemoryStore(2, 10)
+	store.Set("a", &cache.Entry{URL: "a", Body: []byte("1")})
+	store.Set("b", &cache.Entry{URL: "b", Body: []byte("2")})
+	store.Get("a")
+	store.Set("c", &cache.Entry{URL: "c", Body: []byte("3")})
+	if _, ok := store.Get("b"); ok {
+		t.Error("最近最少使用的条目 b 应被淘汰")
+	}
+	if _, ok := store.Get("a"); !ok {
+		t.Error("条目 a 不应被淘汰")
+	}
+
+	// 超过字节上限时淘汰
+	store.Set("d", &cache.Entry{URL: "d", Body: []byte("1234567890")})
+	if store.Len() != 1 {
+		t.Errorf("超过字节上限后期望只剩 1 个条目, 实际: %d", store.Len())
+	}
+	store.Set("e", &cache.Entry{URL: "e", Body: make([]byte, 11)})
+	if _, ok := store.Get("e"); ok {
+		t.Error("大于字节上限的条目不应被存储")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/core"
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/reqstream"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// newSlowServer 创建一个延迟响应的测试服务器
+func newSlowServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			_, _ = w.Write([]byte("ok"))
+		case <-r.Context().Done():
+		}
+	}))
+}
+
+// TestSingleDoContextDeadline 上下文超时取消单次请求
+func TestSingleDoContextDeadline(t *testing.T) {
+	server := newSlowServer(2 * time.Second)
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	resp := requester.DoContext(ctx, &request.Request{
+		Method: method.GET,
+		URL:    server.URL,
+	})
+	if !errors.Is(resp.Error, core.ErrRequestCanceled) {
+		t.Fatalf("期望取消错误, 实际: %v", resp.Error)
+	}
+	if !errors.Is(resp.Error, context.DeadlineExceeded) {
+		t.Fatalf("期望 DeadlineExceeded, 实际: %v", resp.Error)
+	}
+	t.Logf("请求错误: %v\n", resp.Error)
+}
+
+// TestBatchDoContextCancel 取消上下文后批量请求全部返回取消错误
+func TestBatchDoContextCancel(t *testing.T) {
+	server := newSlowServer(2 * time.Second)
+	defer server.Close()
+
+	batchRequester := reqbatch.NewBatchRequester(false)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	requests := []*request.Request{}
+	for i := 0; i < 5; i++ {
+		requests = append(requests, &request.Request{
+			Method: method.GET,
+			URL:    server.URL,
+		})
+	}
+
+	for _, resp := range batchRequester.DoContext(ctx, requests) {
+		if !errors.Is(resp.Error, context.Canceled) {
+			t.Fatalf("期望 context.Canceled, 实际: %v", resp.Error)
+		}
+	}
+}
+
+// TestStreamDoContextCanceled 已取消的上下文仍然产生一个响应
+func TestStreamDoContextCanceled(t *testing.T) {
+	streamRequester := reqstream.NewStreamRequester(false, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	streamRequester.DoContext(ctx, &request.Request{
+		Method: method.GET,
+		URL:    "http://127.0.0.1:1",
+	})
+
+	resp := <-streamRequester.ResponseCh()
+	if !errors.Is(resp.Error, core.ErrRequestCanceled) {
+		t.Fatalf("期望取消错误, 实际: %v", resp.Error)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoEnthusiast/httpreq/core"
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/reqstream"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// newEchoHeaderServer 创建一个返回请求路径和部分请求头的测试服务器
+func newEchoHeaderServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path + "|" + r.Header.Get("Authorization") + "|" + r.Header.Get("User-Agent") + "|" + r.Header.Get("Content-Type")))
+	}))
+}
+
+// TestBatchBaseURLAndDefaultHeaders 相对地址基于基础地址解析，默认请求头可被请求头覆盖，且不修改调用方的请求头
+func TestBatchBaseURLAndDefaultHeaders(t *testing.T) {
+	server := newEchoHeaderServer()
+	defer server.Close()
+
+	batchRequester := reqbatch.NewBatchRequester(false,
+		core.WithBaseURL(server.URL+"/api/v1"),
+		core.WithHeader("Authorization", "Bearer default"),
+		core.WithHeader("User-Agent", "httpreq-test"),
+	)
+
+	header := http.Header{"authorization": {"Bearer override"}}
+	requests := []*request.Request{
+		{Method: method.GET, URL: "users", Meta: map[string]interface{}{"want": "/api/v1/users|Bearer default|httpreq-test|"}},
+		{Method: method.POST, URL: "/items", Header: header, ContentType: method.ContentTypeText, Body: "x",
+			Meta: map[string]interface{}{"want": "/api/v1/items|Bearer override|httpreq-test|text/plain"}},
+		{Method: method.GET, URL: server.URL + "/absolute", Meta: map[string]interface{}{"want": "/absolute|Bearer default|httpreq-test|"}},
+	}
+	for _, resp := range batchRequester.Do(requests) {
+		if resp.Error != nil {
+			t.Fatalf("请求错误: %v", resp.Error)
+		}
+		if want := resp.Request.Meta["want"]; string(resp.ResponseBody) != want {
+			t.Errorf("期望 %s, 实际 %s", want, resp.ResponseBody)
+		}
+	}
+	if len(header) != 1 || header.Get("Content-Type") != "" {
+		t.Errorf("调用方的请求头被修改: %v", header)
+	}
+
+	if err := batchRequester.SetBaseURL("relative/path"); err == nil {
+		t.Errorf("期望相对基础地址报错")
+	}
+}
+
+// TestStreamSetBaseURL 流式请求器通过设置方法修改基础地址和默认请求头
+func TestStreamSetBaseURL(t *testing.T) {
+	server := newEchoHeaderServer()
+	defer server.Close()
+
+	streamRequester := reqstream.NewStreamRequester(false, 1, core.WithBaseURL("://invalid"))
+	streamRequester.Do(&request.Request{Method: method.GET, URL: "users"})
+	if resp := <-streamRequester.ResponseCh(); reqerr.KindOf(resp.Error) != reqerr.KindInvalidRequest {
+		t.Fatalf("期望无效请求错误, 实际: %v", resp.Error)
+	}
+
+	if err := streamRequester.SetBaseURL(server.URL + "/"); err != nil {
+		t.Fatal(err)
+	}
+	streamRequester.SetHeader("User-Agent", "stream")
+	streamRequester.Do(&request.Request{Method: method.GET, URL: "users"})
+	if resp := <-streamRequester.ResponseCh(); string(resp.ResponseBody) != "/users||stream|" {
+		t.Errorf("响应错误: %s %v", resp.ResponseBody, resp.Error)
+	}
+}
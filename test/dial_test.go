@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// newUnixServer 在临时目录中启动一个监听 Unix 套接字的测试服务器，返回套接字路径
+func newUnixServer(t *testing.T, name string) string {
+	dir, err := os.MkdirTemp("", "httpreq")
+	if err != nil {
+		t.Fatalf("创建临时目录失败: %v", err)
+	}
+	socketPath := filepath.Join(dir, "s.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("监听 Unix 套接字失败: %v", err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(name + " " + r.Host + " " + r.URL.RequestURI()))
+	})}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = os.RemoveAll(dir)
+	})
+	return socketPath
+}
+
+// TestSingleUnixSocket unix:// 地址和按主机映射的 Unix 套接字，不同套接字的请求不共享连接
+func TestSingleUnixSocket(t *testing.T) {
+	socketA, socketB := newUnixServer(t, "a"), newUnixServer(t, "b")
+
+	requester := reqsingle.NewSingleRequester(false)
+	resp := requester.Do(&request.Request{Method: method.GET, URL: "unix://" + socketA + ":/v1/info", Query: map[string]string{"all": "1"}})
+	if resp.Error != nil || string(resp.ResponseBody) != "a localhost /v1/info?all=1" {
+		t.Fatalf("unix:// 请求失败: %v %s", resp.Error, resp.ResponseBody)
+	}
+	resp = requester.Do(&request.Request{Method: method.GET, URL: "unix://" + socketB + ":/v1/info"})
+	if resp.Error != nil || string(resp.ResponseBody) != "b localhost /v1/info" {
+		t.Errorf("不同套接字的请求不应共享连接: %v %s", resp.Error, resp.ResponseBody)
+	}
+
+	// 按主机映射套接字
+	requester.SetUnixSocket("daemon.local", socketB)
+	resp = requester.Do(&request.Request{Method: method.GET, URL: "http://daemon.local/ping"})
+	if resp.Error != nil || string(resp.ResponseBody) != "b daemon.local /ping" {
+		t.Errorf("按主机映射的套接字未生效: %v %s", resp.Error, resp.ResponseBody)
+	}
+	requester.SetUnixSocket("daemon.local", "")
+	if resp = requester.Do(&request.Request{Method: method.GET, URL: "http://daemon.local/ping"}); resp.Error == nil {
+		t.Error("移除映射后请求应失败")
+	}
+}
+
+// TestBatchDialContext 自定义拨号钩子用于所有连接，包括 Unix 套接字
+func TestBatchDialContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("tcp"))
+	}))
+	defer server.Close()
+	socketPath := newUnixServer(t, "unix")
+
+	var dials atomic.Int32
+	batchRequester := reqbatch.NewBatchRequester(false)
+	batchRequester.SetDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials.Add(1)
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	})
+	want := map[string]string{server.URL: "tcp", "unix://" + socketPath: "unix localhost /"}
+	responses := batchRequester.Do([]*request.Request{
+		{Method: method.GET, URL: server.URL},
+		{Method: method.GET, URL: "unix://" + socketPath},
+	})
+	for _, resp := range responses {
+		if resp.Error != nil || string(resp.ResponseBody) != want[resp.Request.URL] {
+			t.Errorf("请求失败: %s %v %s", resp.Request.URL, resp.Error, resp.ResponseBody)
+		}
+	}
+	if dials.Load() != 2 {
+		t.Errorf("期望拨号钩子被调用 2 次, 实际: %d", dials.Load())
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/core"
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// TestSingleErrorKinds 不同失败原因被归类为对应的错误类型，并保留原始错误链
+func TestSingleErrorKinds(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	// 获取一个未监听的本地端口
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := listener.Addr().String()
+	_ = listener.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	cases := []struct {
+		name      string
+		req       *request.Request
+		kind      reqerr.Kind
+		sentinel  error
+		retryable bool
+	}{
+		{"连接失败", &request.Request{Method: method.GET, URL: "http://" + closedAddr}, reqerr.KindConnect, reqerr.ErrConnect, true},
+		{"TLS 校验失败", &request.Request{Method: method.GET, URL: tlsServer.URL}, reqerr.KindTLS, reqerr.ErrTLS, false},
+		{"无效请求", &request.Request{Method: method.GET, URL: "ftp://127.0.0.1/file"}, reqerr.KindInvalidRequest, reqerr.ErrInvalidRequest, false},
+		{"代理连接失败", &request.Request{Method: method.GET, URL: "http://example.com", Proxy: "http://" + closedAddr}, reqerr.KindProxy, reqerr.ErrProxy, true},
+		{"无效代理", &request.Request{Method: method.GET, URL: "http://example.com", Proxy: 123}, reqerr.KindProxy, reqerr.ErrProxy, false},
+	}
+	for _, c := range cases {
+		resp := requester.Do(c.req)
+		if resp.Error == nil {
+			t.Fatalf("%s: 期望错误", c.name)
+		}
+		if kind := reqerr.KindOf(resp.Error); kind != c.kind {
+			t.Fatalf("%s: 期望类型 %s, 实际 %s (%v)", c.name, c.kind, kind, resp.Error)
+		}
+		if !errors.Is(resp.Error, c.sentinel) {
+			t.Fatalf("%s: errors.Is 匹配失败: %v", c.name, resp.Error)
+		}
+		if reqerr.IsRetryable(resp.Error) != c.retryable {
+			t.Fatalf("%s: 期望 Retryable=%v: %v", c.name, c.retryable, resp.Error)
+		}
+		t.Logf("%s: %v\n", c.name, resp.Error)
+	}
+
+	// 原始错误链被保留
+	resp := requester.Do(&request.Request{Method: method.GET, URL: "http://" + closedAddr})
+	var opErr *net.OpError
+	if !errors.As(resp.Error, &opErr) {
+		t.Fatalf("期望 *net.OpError, 实际: %v", resp.Error)
+	}
+}
+
+// TestSingleTimeoutErrorKind 超时错误同时匹配 reqerr.ErrTimeout、core.ErrRequestTimeout 和 context.DeadlineExceeded
+func TestSingleTimeoutErrorKind(t *testing.T) {
+	server := newSlowServer(2 * time.Second)
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	resp := requester.Do(&request.Request{
+		Method:  method.GET,
+		URL:     server.URL,
+		Timeout: 100 * time.Millisecond,
+	})
+	if !errors.Is(resp.Error, reqerr.ErrTimeout) || !errors.Is(resp.Error, core.ErrRequestTimeout) {
+		t.Fatalf("期望超时错误, 实际: %v", resp.Error)
+	}
+	if !errors.Is(resp.Error, context.DeadlineExceeded) {
+		t.Fatalf("期望 DeadlineExceeded, 实际: %v", resp.Error)
+	}
+	if !reqerr.IsTemporary(resp.Error) {
+		t.Fatalf("期望超时错误是暂时性的: %v", resp.Error)
+	}
+}
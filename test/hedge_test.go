@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/hedge"
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// newSlowFirstServer 创建一个第一个请求很慢、之后的请求立即返回的测试服务器，canceled 在慢请求被取消时关闭
+func newSlowFirstServer(hits *atomic.Int32, canceled chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+				close(canceled)
+			case <-time.After(2 * time.Second):
+			}
+			return
+		}
+		_, _ = w.Write([]byte("fast"))
+	}))
+}
+
+// TestSingleHedgedRequest 原始请求未在延迟内回复时发送对冲副本，先成功的副本胜出，另一个被取消
+func TestSingleHedgedRequest(t *testing.T) {
+	var hits atomic.Int32
+	canceled := make(chan struct{})
+	server := newSlowFirstServer(&hits, canceled)
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	start := time.Now()
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL, Hedge: hedge.NewPolicy(50 * time.Millisecond)})
+	if resp.Error != nil || string(resp.ResponseBody) != "fast" {
+		t.Fatalf("对冲请求失败: %v %s", resp.Error, resp.ResponseBody)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("对冲未降低耗时: %v", elapsed)
+	}
+	if resp.Hedges != 1 || resp.HedgeWinner != 1 {
+		t.Errorf("期望第 1 个对冲副本胜出, 实际: Hedges=%d HedgeWinner=%d", resp.Hedges, resp.HedgeWinner)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("落败的原始请求应被取消")
+	}
+}
+
+// TestSingleHedgeSkipsPost 非幂等的 POST 请求和写入 BodyWriter 的请求不会被对冲
+func TestSingleHedgeSkipsPost(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte("report"))
+	}))
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetHedgePolicy(hedge.NewPolicy(10 * time.Millisecond))
+	resp := requester.Do(&request.Request{Method: method.POST, URL: server.URL, ContentType: method.ContentTypeText, Body: "x"})
+	if resp.Error != nil || hits.Load() != 1 || resp.Hedges != 0 {
+		t.Errorf("POST 不应被对冲: %v 服务器收到 %d 个请求, Hedges=%d", resp.Error, hits.Load(), resp.Hedges)
+	}
+
+	// 多个副本会同时写入同一个 BodyWriter
+	var sink bytes.Buffer
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL, BodyWriter: &sink})
+	if resp.Error != nil || hits.Load() != 2 || resp.Hedges != 0 || sink.String() != "report" {
+		t.Errorf("写入 BodyWriter 的请求不应被对冲: %v 服务器收到 %d 个请求, Hedges=%d, 写入: %q", resp.Error, hits.Load(), resp.Hedges, sink.String())
+	}
+}
+
+// TestBatchHedgePercentile 批量请求共享百分位数对冲策略，策略从最近的响应中学习延迟
+func TestBatchHedgePercentile(t *testing.T) {
+	policy := hedge.NewPercentilePolicy(0.9, time.Second)
+	policy.MinSamples = 5
+	if delay := policy.HedgeDelay(); delay != time.Second {
+		t.Errorf("样本不足时应使用后备延迟, 实际: %v", delay)
+	}
+	for i := 1; i <= 10; i++ {
+		policy.Observe(time.Duration(i) * 10 * time.Millisecond)
+	}
+	if delay := policy.HedgeDelay(); delay != 90*time.Millisecond {
+		t.Errorf("期望 P90 为 90ms, 实际: %v", delay)
+	}
+
+	var hits atomic.Int32
+	canceled := make(chan struct{})
+	server := newSlowFirstServer(&hits, canceled)
+	defer server.Close()
+
+	batchRequester := reqbatch.NewBatchRequester(false)
+	batchRequester.SetHedgePolicy(policy)
+	responses := batchRequester.Do([]*request.Request{{Method: method.GET, URL: server.URL}})
+	if resp := responses[0]; resp.Error != nil || resp.HedgeWinner != 1 {
+		t.Errorf("期望对冲副本胜出: %v HedgeWinner=%d", resp.Error, resp.HedgeWinner)
+	}
+}
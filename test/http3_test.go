@@ -0,0 +1,155 @@
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/transportsetting"
+	"github.com/GoEnthusiast/httpreq/types/request"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// newHTTP3Server 使用 TLS 测试服务器的证书在回环地址上启动一个 HTTP/3 服务器，返回其 UDP 端口
+func newHTTP3Server(t *testing.T, tlsServer *httptest.Server, handler http.Handler) int {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("监听 UDP 失败: %v", err)
+	}
+	server := &http3.Server{Handler: handler, TLSConfig: http3.ConfigureTLSConfig(tlsServer.TLS.Clone())}
+	go func() { _ = server.Serve(conn) }()
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = conn.Close()
+	})
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// echoProtoHandler 在响应体中返回 name、协议和请求体
+func echoProtoHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(name + " " + r.Proto + " " + string(body)))
+	})
+}
+
+// TestSingleHTTP3Only 仅使用 HTTP/3 时请求通过 QUIC 发送，UDP 端口不可达时报错而不回退
+func TestSingleHTTP3Only(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(echoProtoHandler("tcp"))
+	defer tlsServer.Close()
+	port := newHTTP3Server(t, tlsServer, echoProtoHandler("h3"))
+	_, tcpPort, _ := net.SplitHostPort(tlsServer.Listener.Addr().String())
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetTransport(tlsServer.Client().Transport.(*http.Transport).Clone())
+	requester.SetHTTP3(transportsetting.HTTP3Only)
+	requester.SetConnectTimeout(300 * time.Millisecond)
+
+	url := "https://127.0.0.1:" + strconv.Itoa(port) + "/"
+	resp := requester.Do(&request.Request{Method: method.POST, URL: url, ContentType: method.ContentTypeText, Body: "a=1"})
+	if resp.Error != nil || resp.Proto != "HTTP/3.0" || string(resp.ResponseBody) != "h3 HTTP/3.0 a=1" {
+		t.Fatalf("HTTP/3 请求失败: %v %s %s", resp.Error, resp.Proto, resp.ResponseBody)
+	}
+
+	// TCP 端口上没有 HTTP/3 服务，仅 HTTP/3 模式不回退到 TCP
+	resp = requester.Do(&request.Request{Method: method.GET, URL: "https://127.0.0.1:" + tcpPort + "/"})
+	if resp.Error == nil {
+		t.Errorf("仅 HTTP/3 模式不应回退到 TCP: %s %s", resp.Proto, resp.ResponseBody)
+	}
+}
+
+// TestSingleHTTP3ResetInFlight 修改设置清空 HTTP/3 传输层池时，进行中的请求不会被中止
+func TestSingleHTTP3ResetInFlight(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(echoProtoHandler("tcp"))
+	defer tlsServer.Close()
+	started := make(chan struct{})
+	port := newHTTP3Server(t, tlsServer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte("slow " + r.Proto))
+	}))
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetTransport(tlsServer.Client().Transport.(*http.Transport).Clone())
+	requester.SetHTTP3(transportsetting.HTTP3Only)
+
+	done := make(chan string, 1)
+	go func() {
+		resp := requester.Do(&request.Request{Method: method.GET, URL: "https://127.0.0.1:" + strconv.Itoa(port) + "/"})
+		if resp.Error != nil {
+			done <- resp.Error.Error()
+			return
+		}
+		done <- string(resp.ResponseBody)
+	}()
+	<-started
+	requester.SetHTTP3(transportsetting.HTTP3Only)
+	if body := <-done; body != "slow HTTP/3.0" {
+		t.Errorf("进行中的 HTTP/3 请求不应被中止: %s", body)
+	}
+}
+
+// TestSingleHTTP3AltSvc 服务器通过 Alt-Svc 声明 h3 后，后续请求升级到 HTTP/3
+func TestSingleHTTP3AltSvc(t *testing.T) {
+	var port int
+	tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Alt-Svc", `h3=":`+strconv.Itoa(port)+`"; ma=60`)
+		_, _ = w.Write([]byte("tcp " + r.Proto))
+	}))
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+	port = newHTTP3Server(t, tlsServer, echoProtoHandler("h3"))
+
+	requester := reqsingle.NewSingleRequester(true)
+	requester.SetTransport(tlsServer.Client().Transport.(*http.Transport).Clone())
+	requester.SetHTTP3(transportsetting.HTTP3AltSvc)
+
+	resp := requester.Do(&request.Request{Method: method.GET, URL: tlsServer.URL})
+	if resp.Error != nil || resp.Proto != "HTTP/2.0" {
+		t.Fatalf("第一次请求应通过 TCP 发送: %v %s %s", resp.Error, resp.Proto, resp.ResponseBody)
+	}
+	resp = requester.Do(&request.Request{Method: method.GET, URL: tlsServer.URL})
+	if resp.Error != nil || string(resp.ResponseBody) != "h3 HTTP/3.0 " {
+		t.Errorf("声明 Alt-Svc 后应升级到 HTTP/3: %v %s %s", resp.Error, resp.Proto, resp.ResponseBody)
+	}
+}
+
+// TestBatchHTTP3Fallback 优先 HTTP/3 时 QUIC 连接失败回退到 TCP，请求体被重新发送
+func TestBatchHTTP3Fallback(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(echoProtoHandler("tcp"))
+	defer tlsServer.Close()
+
+	batchRequester := reqbatch.NewBatchRequester(false)
+	batchRequester.SetTransport(tlsServer.Client().Transport.(*http.Transport).Clone())
+	batchRequester.SetHTTP3(transportsetting.HTTP3Preferred)
+	batchRequester.SetConnectTimeout(300 * time.Millisecond)
+
+	responses := batchRequester.Do([]*request.Request{
+		{Method: method.POST, URL: tlsServer.URL, ContentType: method.ContentTypeText, Body: "n=1"},
+		{Method: method.GET, URL: tlsServer.URL},
+	})
+	for _, resp := range responses {
+		if resp.Error != nil || !strings.HasPrefix(string(resp.ResponseBody), "tcp HTTP/1.1") {
+			t.Fatalf("应回退到 TCP: %v %s", resp.Error, resp.ResponseBody)
+		}
+		if resp.Request.Method == method.POST && string(resp.ResponseBody) != "tcp HTTP/1.1 n=1" {
+			t.Errorf("回退时应重新发送请求体: %s", resp.ResponseBody)
+		}
+	}
+
+	// 失败的源在一段时间内直接使用 TCP
+	start := time.Now()
+	resp := batchRequester.Do([]*request.Request{{Method: method.GET, URL: tlsServer.URL}})[0]
+	if resp.Error != nil || time.Since(start) > 200*time.Millisecond {
+		t.Errorf("失败的源应直接使用 TCP: %v %v", resp.Error, time.Since(start))
+	}
+}
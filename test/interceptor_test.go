@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoEnthusiast/httpreq/interceptor"
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+	"github.com/GoEnthusiast/httpreq/types/response"
+)
+
+// TestSingleInterceptorHooks 请求前注入请求头，响应后修改响应，出错时回调
+func TestSingleInterceptorHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.OnBeforeRequest(func(req *request.Request, httpReq *http.Request) error {
+		httpReq.Header.Set("Authorization", "Bearer token")
+		return nil
+	})
+	requester.OnAfterResponse(func(resp *response.Response) error {
+		resp.ResponseBody = append([]byte("intercepted "), resp.ResponseBody...)
+		return nil
+	})
+	var failed int
+	requester.OnError(func(resp *response.Response) {
+		failed++
+	})
+
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL})
+	if resp.Error != nil {
+		t.Fatalf("请求错误: %v", resp.Error)
+	}
+	if string(resp.ResponseBody) != "intercepted Bearer token" {
+		t.Fatalf("拦截器未生效: %s", resp.ResponseBody)
+	}
+
+	resp = requester.Do(&request.Request{Method: method.GET, URL: "http://127.0.0.1:1"})
+	if resp.Error == nil || failed != 1 {
+		t.Fatalf("错误钩子未被调用: %v, %d", resp.Error, failed)
+	}
+}
+
+// TestBatchMiddlewareShortCircuit 中间件直接返回缓存响应，不发送请求
+func TestBatchMiddlewareShortCircuit(t *testing.T) {
+	errAborted := errors.New("aborted")
+	batchRequester := reqbatch.NewBatchRequester(false)
+	batchRequester.Use(func(next interceptor.Handler) interceptor.Handler {
+		return func(ctx context.Context, req *request.Request) *response.Response {
+			if req.Meta["cached"] == true {
+				return &response.Response{Request: req, ResponseStatusCode: http.StatusOK, ResponseBody: []byte("cached")}
+			}
+			return next(ctx, req)
+		}
+	})
+	batchRequester.OnBeforeRequest(func(req *request.Request, httpReq *http.Request) error {
+		return errAborted
+	})
+
+	responses := batchRequester.Do([]*request.Request{
+		{Method: method.GET, URL: "http://127.0.0.1:1", Meta: map[string]interface{}{"cached": true}},
+		{Method: method.GET, URL: "http://127.0.0.1:1"},
+	})
+	for _, resp := range responses {
+		if resp.Request.Meta["cached"] == true {
+			if string(resp.ResponseBody) != "cached" || resp.EndTime.IsZero() {
+				t.Fatalf("中间件短路失败: %+v", resp)
+			}
+		} else if !errors.Is(resp.Error, errAborted) {
+			t.Fatalf("请求前钩子未中止请求: %v", resp.Error)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// searchQuery 通过 url 标签描述查询参数
+type searchQuery struct {
+	Keyword string   `url:"q"`
+	Page    int      `url:"page,omitempty"`
+	Tags    []string `url:"tag"`
+	Debug   bool     `url:"-"`
+}
+
+// TestSingleQueryAndPathParams 查询参数与路径参数被正确合并和转义
+func TestSingleQueryAndPathParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.EscapedPath() + "?" + r.URL.RawQuery))
+	}))
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	cases := []struct {
+		name   string
+		req    *request.Request
+		path   string
+		values url.Values
+	}{
+		{
+			name: "map 与原有查询参数合并",
+			req: &request.Request{
+				Method: method.GET,
+				URL:    server.URL + "/search?lang=zh&page=1",
+				Query:  map[string]interface{}{"q": "a&b c", "page": 2},
+			},
+			path:   "/search",
+			values: url.Values{"lang": {"zh"}, "page": {"2"}, "q": {"a&b c"}},
+		},
+		{
+			name: "结构体标签",
+			req: &request.Request{
+				Method: method.GET,
+				URL:    server.URL + "/search",
+				Query:  &searchQuery{Keyword: "中文", Tags: []string{"x", "y"}, Debug: true},
+			},
+			path:   "/search",
+			values: url.Values{"q": {"中文"}, "tag": {"x", "y"}},
+		},
+		{
+			name: "路径参数",
+			req: &request.Request{
+				Method:     method.GET,
+				URL:        server.URL + "/users/{id}/files/{name}",
+				PathParams: map[string]string{"id": "42", "name": "a b/c.txt"},
+				Query:      url.Values{"v": {"1"}},
+			},
+			path:   "/users/42/files/a%20b%2Fc.txt",
+			values: url.Values{"v": {"1"}},
+		},
+	}
+	for _, c := range cases {
+		resp := requester.Do(c.req)
+		if resp.Error != nil {
+			t.Fatalf("%s: 请求错误: %v", c.name, resp.Error)
+		}
+		got, err := url.Parse(string(resp.ResponseBody))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.EscapedPath() != c.path || got.Query().Encode() != c.values.Encode() {
+			t.Errorf("%s: 期望 %s?%s, 实际 %s", c.name, c.path, c.values.Encode(), resp.ResponseBody)
+		}
+	}
+
+	// 只替换 Query 中的参数，原有查询字符串的顺序、转义和分号保持不变
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL + "/search?b=2&page=1&a=%7e;x#top", Query: url.Values{"page": {"3"}}})
+	if resp.Error != nil || string(resp.ResponseBody) != "/search?b=2&a=%7e;x&page=3" {
+		t.Errorf("原有查询字符串应保持不变: %v %s", resp.Error, resp.ResponseBody)
+	}
+
+	// 缺少路径参数
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL + "/users/{id}"})
+	if reqerr.KindOf(resp.Error) != reqerr.KindInvalidRequest {
+		t.Errorf("期望无效请求错误, 实际: %v", resp.Error)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/transportsetting"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// newNamedServer 创建一个在响应体中返回 name、Host 请求头和请求路径的测试服务器，也可以作为 HTTP 正向代理使用；
+// status 不为 nil 且不为零时以该状态码响应
+func newNamedServer(name string, status *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != nil {
+			if code := int(status.Load()); code != 0 {
+				w.WriteHeader(code)
+			}
+		}
+		_, _ = w.Write([]byte(namedBody(name, r.Host, r.URL.Path)))
+	}))
+}
+
+// namedBody 返回 newNamedServer 创建的服务器对 host 上 path 的响应体
+func namedBody(name, host, path string) string {
+	return name + " " + host + path
+}
+
+// TestProxyTransportPoolCap 所有传输层都在使用时传输层池可以超过上限，响应体关闭后缩减回上限
+func TestProxyTransportPoolCap(t *testing.T) {
+	proxies := make([]*httptest.Server, 4)
+	for i := range proxies {
+		proxies[i] = newNamedServer(strconv.Itoa(i), nil)
+		defer proxies[i].Close()
+	}
+
+	setting := transportsetting.NewTransportSetting(false)
+	setting.SetMaxProxyTransports(2)
+	if err := setting.SetProxy(func(r *http.Request) (*url.URL, error) {
+		return url.Parse(proxies[len(r.URL.Path)-1].URL)
+	}); err != nil {
+		t.Fatalf("设置代理失败: %v", err)
+	}
+
+	// 不关闭响应体，每个传输层都有进行中的请求
+	var bodies []io.Closer
+	for i := range proxies {
+		req, _ := http.NewRequest(http.MethodGet, "http://example.invalid/"+strings.Repeat("x", i), nil)
+		resp, err := setting.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("请求错误: %v", err)
+		}
+		bodies = append(bodies, resp.Body)
+	}
+	if n := setting.PooledTransports(); n != len(proxies) {
+		t.Errorf("所有传输层都在使用时期望 %d 个传输层, 实际: %d", len(proxies), n)
+	}
+	for _, body := range bodies {
+		_ = body.Close()
+	}
+	if n := setting.PooledTransports(); n != 2 {
+		t.Errorf("响应体关闭后期望缩减到 2 个传输层, 实际: %d", n)
+	}
+}
+
+// TestBatchPerRequestProxyIsolation 并发请求中每个请求都使用自己的代理
+func TestBatchPerRequestProxyIsolation(t *testing.T) {
+	proxyA := newNamedServer("A", nil)
+	defer proxyA.Close()
+	proxyB := newNamedServer("B", nil)
+	defer proxyB.Close()
+
+	batchRequester := reqbatch.NewBatchRequester(false)
+
+	requests := []*request.Request{}
+	for i := 0; i < 50; i++ {
+		name, proxy := "A", proxyA.URL
+		if i%2 == 1 {
+			name, proxy = "B", proxyB.URL
+		}
+		requests = append(requests, &request.Request{
+			Method: method.GET,
+			URL:    "http://example.invalid/ip",
+			Proxy:  proxy,
+			Meta:   map[string]interface{}{"proxy": name},
+		})
+	}
+
+	for _, resp := range batchRequester.Do(requests) {
+		if resp.Error != nil {
+			t.Fatalf("请求错误: %v", resp.Error)
+		}
+		if got, want := strings.Fields(string(resp.ResponseBody))[0], resp.Request.Meta["proxy"]; got != want {
+			t.Fatalf("请求经过了错误的代理: 期望 %v, 实际 %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/proxypool"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// TestSingleProxyPoolBan 代理池轮换代理，返回 407 的代理被封禁，响应记录所用代理，全部被封禁时报告代理错误
+func TestSingleProxyPoolBan(t *testing.T) {
+	var okStatus, badStatus atomic.Int32
+	badStatus.Store(http.StatusProxyAuthRequired)
+	good1, good2, bad := newNamedServer("p1", &okStatus), newNamedServer("p2", &okStatus), newNamedServer("bad", &badStatus)
+	defer good1.Close()
+	defer good2.Close()
+	defer bad.Close()
+
+	pool, err := proxypool.New(proxypool.Config{Proxies: []string{good1.URL, bad.URL, good2.URL}, MaxFails: 1})
+	if err != nil {
+		t.Fatalf("创建代理池失败: %v", err)
+	}
+	defer pool.Close()
+	requester := reqsingle.NewSingleRequester(false)
+	if err := requester.SetProxy(pool); err != nil {
+		t.Fatalf("设置代理池失败: %v", err)
+	}
+
+	var used []string
+	for i := 0; i < 5; i++ {
+		resp := requester.Do(&request.Request{Method: method.GET, URL: "http://target.example/"})
+		if resp.Error != nil {
+			t.Fatalf("请求错误: %v", resp.Error)
+		}
+		used = append(used, resp.Proxy)
+	}
+	if used[0] != good1.URL || used[1] != bad.URL || used[2] != good2.URL || used[3] != good1.URL || used[4] != good2.URL {
+		t.Errorf("返回 407 的代理应在失败后被跳过: %v", used)
+	}
+	if status := pool.Status(); !status[1].Banned || status[0].Banned || status[1].Requests != 1 {
+		t.Errorf("期望代理 bad 被封禁: %+v", status)
+	}
+
+	// 单请求代理池，所有代理都被封禁
+	single, _ := proxypool.New(proxypool.Config{Proxies: []string{bad.URL}, MaxFails: 1})
+	requester.Do(&request.Request{Method: method.GET, URL: "http://target.example/", Proxy: single})
+	resp := requester.Do(&request.Request{Method: method.GET, URL: "http://target.example/", Proxy: single})
+	if !errors.Is(resp.Error, proxypool.ErrNoProxy) || !errors.Is(resp.Error, reqerr.ErrProxy) {
+		t.Errorf("期望没有可用代理的错误, 实际: %v", resp.Error)
+	}
+}
+
+// TestSingleProxyPoolBanHung 没有响应的代理在总超时或响应头超时后计为失败并被封禁
+func TestSingleProxyPoolBanHung(t *testing.T) {
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer hung.Close()
+
+	pool, err := proxypool.New(proxypool.Config{Proxies: []string{hung.URL}, MaxFails: 2})
+	if err != nil {
+		t.Fatalf("创建代理池失败: %v", err)
+	}
+	defer pool.Close()
+	requester := reqsingle.NewSingleRequester(false)
+	if err := requester.SetProxy(pool); err != nil {
+		t.Fatalf("设置代理池失败: %v", err)
+	}
+
+	for _, req := range []*request.Request{
+		{Method: method.GET, URL: "http://target.example/", Timeout: 100 * time.Millisecond},
+		{Method: method.GET, URL: "http://target.example/", HeaderTimeout: 100 * time.Millisecond},
+	} {
+		if resp := requester.Do(req); !errors.Is(resp.Error, reqerr.ErrTimeout) {
+			t.Fatalf("期望超时错误, 实际: %v", resp.Error)
+		}
+	}
+	if status := pool.Status(); !status[0].Banned {
+		t.Errorf("期望无响应的代理被封禁: %+v", status)
+	}
+}
+
+// TestBatchProxyPoolSticky 按 Meta 会话键和目标主机固定代理，代理列表可以从文件加载
+func TestBatchProxyPoolSticky(t *testing.T) {
+	var okStatus atomic.Int32
+	proxies := make([]*httptest.Server, 3)
+	var lines []string
+	for i := range proxies {
+		proxies[i] = newNamedServer(string(rune('a'+i)), &okStatus)
+		defer proxies[i].Close()
+		lines = append(lines, strings.TrimPrefix(proxies[i].URL, "http://"))
+	}
+	path := filepath.Join(t.TempDir(), "proxies.txt")
+	if err := os.WriteFile(path, []byte("# 代理列表\n"+strings.Join(lines, "\n\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("写入代理文件失败: %v", err)
+	}
+	list, err := proxypool.Load(path)
+	if err != nil || len(list) != 3 {
+		t.Fatalf("加载代理文件失败: %v %v", list, err)
+	}
+
+	pool, err := proxypool.New(proxypool.Config{Proxies: list, Strategy: proxypool.StickyKey, SessionKey: "session"})
+	if err != nil {
+		t.Fatalf("创建代理池失败: %v", err)
+	}
+	batchRequester := reqbatch.NewBatchRequester(false)
+	_ = batchRequester.SetProxy(pool)
+	var requests []*request.Request
+	for i := 0; i < 4; i++ {
+		for _, session := range []string{"s1", "s2", "s3", "s4"} {
+			requests = append(requests, &request.Request{Method: method.GET, URL: "http://target.example/", Meta: map[string]interface{}{"session": session}})
+		}
+	}
+	sessions := map[string]string{}
+	for _, resp := range batchRequester.Do(requests) {
+		if resp.Error != nil {
+			t.Fatalf("请求错误: %v", resp.Error)
+		}
+		session := resp.Request.Meta["session"].(string)
+		if previous, ok := sessions[session]; ok && previous != resp.Proxy {
+			t.Errorf("相同会话应使用同一代理: %s %s %s", session, previous, resp.Proxy)
+		}
+		sessions[session] = resp.Proxy
+	}
+
+	// 按目标主机固定代理
+	hostPool, _ := proxypool.New(proxypool.Config{Proxies: list, Strategy: proxypool.StickyHost})
+	_ = batchRequester.SetProxy(hostPool)
+	hosts := map[string]string{}
+	for _, resp := range batchRequester.Do([]*request.Request{
+		{Method: method.GET, URL: "http://one.example/"}, {Method: method.GET, URL: "http://two.example/"},
+		{Method: method.GET, URL: "http://one.example/x"}, {Method: method.GET, URL: "http://two.example/y"},
+	}) {
+		host, _, _ := strings.Cut(strings.Fields(string(resp.ResponseBody))[1], "/")
+		if previous, ok := hosts[host]; ok && previous != resp.Proxy {
+			t.Errorf("相同主机应使用同一代理: %s %s %s", host, previous, resp.Proxy)
+		}
+		hosts[host] = resp.Proxy
+	}
+}
+
+// TestProxyPoolProbe 健康探测成功后提前解除封禁
+func TestProxyPoolProbe(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusForbidden)
+	proxy := newNamedServer("p", &status)
+	defer proxy.Close()
+
+	pool, err := proxypool.New(proxypool.Config{
+		Proxies:       []string{proxy.URL},
+		MaxFails:      1,
+		BanDuration:   time.Hour,
+		ProbeURL:      "http://probe.example/",
+		ProbeInterval: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("创建代理池失败: %v", err)
+	}
+	defer pool.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	_ = requester.SetProxy(pool)
+	if resp := requester.Do(&request.Request{Method: method.GET, URL: "http://target.example/"}); resp.ResponseStatusCode != http.StatusForbidden {
+		t.Fatalf("期望 403, 实际: %d %v", resp.ResponseStatusCode, resp.Error)
+	}
+	if !pool.Status()[0].Banned {
+		t.Fatal("返回 403 的代理应被封禁")
+	}
+
+	status.Store(0)
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.Status()[0].Banned && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if pool.Status()[0].Banned {
+		t.Fatal("健康探测成功后应解除封禁")
+	}
+	if resp := requester.Do(&request.Request{Method: method.GET, URL: "http://target.example/"}); resp.Error != nil || string(resp.ResponseBody) != namedBody("p", "target.example", "/") {
+		t.Errorf("解除封禁后请求失败: %v %s", resp.Error, resp.ResponseBody)
+	}
+}
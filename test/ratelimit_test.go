@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/ratelimit"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// TestBatchGlobalRateLimit 全局限流器限制批量请求的发送速率，并在响应中报告等待时间
+func TestBatchGlobalRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	batchRequester := reqbatch.NewBatchRequester(false)
+	batchRequester.SetRateLimit(ratelimit.Global(20, 1))
+	var requests []*request.Request
+	for i := 0; i < 5; i++ {
+		requests = append(requests, &request.Request{Method: method.GET, URL: server.URL})
+	}
+
+	start := time.Now()
+	responses := batchRequester.Do(requests)
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("限流未生效, 耗时: %v", elapsed)
+	}
+	var maxWait time.Duration
+	for _, resp := range responses {
+		if resp.Error != nil {
+			t.Fatalf("请求错误: %v", resp.Error)
+		}
+		maxWait = max(maxWait, resp.RateLimitWait)
+	}
+	if maxWait < 150*time.Millisecond {
+		t.Errorf("等待时间报告错误: %v", maxWait)
+	}
+}
+
+// TestBatchPerKeyRateLimit 按主机和按 Meta 键限流，不同的键互不影响
+func TestBatchPerKeyRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// 默认不限制主机，仅对测试服务器的主机单独限流
+	perHost := ratelimit.PerHost(0, 1)
+	perHost.SetLimit(strings.TrimPrefix(server.URL, "http://"), 1000, 10)
+	perTenant := ratelimit.NewLimiter(10, 1, ratelimit.ByMeta("tenant"))
+
+	batchRequester := reqbatch.NewBatchRequester(false)
+	batchRequester.SetRateLimit(perHost, perTenant)
+	newRequest := func(tenant string) *request.Request {
+		req := &request.Request{Method: method.GET, URL: server.URL}
+		if tenant != "" {
+			req.Meta = map[string]interface{}{"tenant": tenant}
+		}
+		return req
+	}
+	requests := []*request.Request{newRequest("a"), newRequest("a"), newRequest("a"), newRequest("b"), newRequest(""), newRequest("")}
+
+	waits := make(map[string]time.Duration)
+	for _, resp := range batchRequester.Do(requests) {
+		if resp.Error != nil {
+			t.Fatalf("请求错误: %v", resp.Error)
+		}
+		tenant, _ := resp.Request.Meta["tenant"].(string)
+		waits[tenant] = max(waits[tenant], resp.RateLimitWait)
+	}
+	if waits["a"] < 150*time.Millisecond {
+		t.Errorf("租户 a 应当被限流, 等待时间: %v", waits["a"])
+	}
+	if waits["b"] > 50*time.Millisecond || waits[""] > 50*time.Millisecond {
+		t.Errorf("其他请求不应等待: b=%v 无租户=%v", waits["b"], waits[""])
+	}
+}
+
+// TestSingleRateLimitCanceled 等待限流时上下文结束，请求以取消错误结束且不会发送
+func TestSingleRateLimitCanceled(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetRateLimit(ratelimit.Global(1, 1))
+	if resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL}); resp.Error != nil {
+		t.Fatalf("请求错误: %v", resp.Error)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	resp := requester.DoContext(ctx, &request.Request{Method: method.GET, URL: server.URL})
+	if !errors.Is(resp.Error, reqerr.ErrCanceled) || !errors.Is(resp.Error, context.DeadlineExceeded) {
+		t.Errorf("期望取消错误, 实际: %v", resp.Error)
+	}
+	if resp.RateLimitWait < 40*time.Millisecond || resp.RateLimitWait > 500*time.Millisecond {
+		t.Errorf("等待时间报告错误: %v", resp.RateLimitWait)
+	}
+	if hits != 1 {
+		t.Errorf("被取消的请求不应发送, 服务器收到 %d 个请求", hits)
+	}
+
+	// 等待限流的时间受请求总超时限制
+	start := time.Now()
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL, Timeout: 50 * time.Millisecond})
+	if !errors.Is(resp.Error, reqerr.ErrTimeout) || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("期望在请求超时后放弃等待, 实际: %v %v", resp.Error, time.Since(start))
+	}
+	if hits != 1 {
+		t.Errorf("超时的请求不应发送, 服务器收到 %d 个请求", hits)
+	}
+
+	// 取消限流后请求立即发送
+	requester.SetRateLimit()
+	if resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL}); resp.Error != nil || resp.RateLimitWait != 0 {
+		t.Errorf("取消限流失败: %v %v", resp.Error, resp.RateLimitWait)
+	}
+}
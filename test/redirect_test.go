@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/redirect"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// newRedirectServer 创建一个 /a -> /b -> /c 的重定向链测试服务器
+func newRedirectServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Hop", "a")
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Hop", "b")
+		http.Redirect(w, r, "/c", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("done"))
+	})
+	mux.HandleFunc("/post", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/c", http.StatusTemporaryRedirect)
+	})
+	return httptest.NewServer(mux)
+}
+
+// TestSingleRedirectHistory 记录完整的重定向链，并支持请求级别的重定向策略
+func TestSingleRedirectHistory(t *testing.T) {
+	server := newRedirectServer()
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL + "/a"})
+	if resp.Error != nil {
+		t.Fatalf("请求错误: %v", resp.Error)
+	}
+	if len(resp.Redirects) != 2 {
+		t.Fatalf("期望 2 次重定向, 实际: %+v", resp.Redirects)
+	}
+	first, second := resp.Redirects[0], resp.Redirects[1]
+	if first.URL != server.URL+"/a" || first.StatusCode != http.StatusFound || first.Header.Get("X-Hop") != "a" || first.Location != server.URL+"/b" {
+		t.Errorf("第一次重定向错误: %+v", first)
+	}
+	if second.URL != server.URL+"/b" || second.StatusCode != http.StatusMovedPermanently || second.Location != server.URL+"/c" {
+		t.Errorf("第二次重定向错误: %+v", second)
+	}
+
+	// 请求级别禁用重定向
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL + "/a", Redirect: redirect.NoFollow()})
+	if resp.Error != nil || resp.ResponseStatusCode != http.StatusFound || resp.Location() != "/b" || len(resp.Redirects) != 0 {
+		t.Errorf("禁用重定向失败: %d %v %+v", resp.ResponseStatusCode, resp.Error, resp.Redirects)
+	}
+
+	// 超过最大重定向次数
+	requester.SetRedirectPolicy(redirect.NewPolicy(1))
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL + "/a"})
+	if !errors.Is(resp.Error, reqerr.ErrRedirect) || len(resp.Redirects) != 1 {
+		t.Errorf("期望重定向次数超限错误, 实际: %v %+v", resp.Error, resp.Redirects)
+	}
+
+	// 307 不重新发送请求体
+	resp = requester.Do(&request.Request{
+		Method:      method.POST,
+		URL:         server.URL + "/post",
+		ContentType: method.ContentTypeText,
+		Body:        "payload",
+		Redirect:    &redirect.Policy{NoRepost: true},
+	})
+	if resp.Error != nil || resp.ResponseStatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("期望返回 307 响应, 实际: %d %v", resp.ResponseStatusCode, resp.Error)
+	}
+}
+
+// TestSingleRedirectAuthHeaders 跨主机重定向时按策略保留或移除认证头
+func TestSingleRedirectAuthHeaders(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer target.Close()
+	// 使用 localhost 访问目标服务器，使其成为不同的主机
+	targetURL := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, targetURL, http.StatusFound)
+	}))
+	defer origin.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	cases := []struct {
+		name   string
+		policy *redirect.Policy
+		auth   string
+	}{
+		{"默认移除", nil, ""},
+		{"保留", &redirect.Policy{Auth: redirect.AuthPreserve}, "Bearer token"},
+		{"仅同主机", &redirect.Policy{SameHostOnly: true}, ""},
+	}
+	for _, c := range cases {
+		resp := requester.Do(&request.Request{
+			Method:   method.GET,
+			URL:      origin.URL,
+			Header:   http.Header{"Authorization": {"Bearer token"}},
+			Redirect: c.policy,
+		})
+		if resp.Error != nil {
+			t.Fatalf("%s: 请求错误: %v", c.name, resp.Error)
+		}
+		if c.policy != nil && c.policy.SameHostOnly {
+			if resp.ResponseStatusCode != http.StatusFound {
+				t.Errorf("%s: 期望停在 302, 实际: %d", c.name, resp.ResponseStatusCode)
+			}
+			continue
+		}
+		if string(resp.ResponseBody) != c.auth {
+			t.Errorf("%s: 期望 Authorization %q, 实际 %q", c.name, c.auth, resp.ResponseBody)
+		}
+	}
+}
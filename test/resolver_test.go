@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/resolver"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// countingResolver 将所有 .test 主机解析到 127.0.0.1 并记录查询次数，其他主机解析失败
+type countingResolver struct {
+	lookups atomic.Int32
+}
+
+func (r *countingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.lookups.Add(1)
+	time.Sleep(20 * time.Millisecond)
+	if strings.HasSuffix(host, ".test") {
+		return []string{"127.0.0.1"}, nil
+	}
+	return nil, errors.New("unknown host " + host)
+}
+
+// TestSingleResolveOverrides 全局和单请求的 host:port 覆盖将真实主机名指向本地服务器，单请求覆盖优先且不共享连接
+func TestSingleResolveOverrides(t *testing.T) {
+	serverA, serverB := newNamedServer("a", nil), newNamedServer("b", nil)
+	defer serverA.Close()
+	defer serverB.Close()
+	addrA, addrB := serverA.Listener.Addr().String(), serverB.Listener.Addr().String()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetResolveOverrides(map[string]string{"api.example.com": addrA})
+
+	resp := requester.Do(&request.Request{Method: method.GET, URL: "http://api.example.com/"})
+	if resp.Error != nil || string(resp.ResponseBody) != namedBody("a", "api.example.com", "/") {
+		t.Fatalf("全局覆盖未生效: %v %s", resp.Error, resp.ResponseBody)
+	}
+
+	// 单请求覆盖优先于全局覆盖
+	resp = requester.Do(&request.Request{Method: method.GET, URL: "http://api.example.com/", Resolve: map[string]string{"api.example.com:80": addrB}})
+	if resp.Error != nil || string(resp.ResponseBody) != namedBody("b", "api.example.com", "/") {
+		t.Fatalf("单请求覆盖未生效: %v %s", resp.Error, resp.ResponseBody)
+	}
+
+	// 之后不带覆盖的请求不会复用发往 b 的连接
+	resp = requester.Do(&request.Request{Method: method.GET, URL: "http://api.example.com/"})
+	if resp.Error != nil || string(resp.ResponseBody) != namedBody("a", "api.example.com", "/") {
+		t.Errorf("不应复用单请求覆盖的连接: %v %s", resp.Error, resp.ResponseBody)
+	}
+
+	// 只有 IP 的覆盖使用请求地址中的端口
+	_, portB, _ := net.SplitHostPort(addrB)
+	requester.SetResolveOverrides(map[string]string{"svc.example.com:" + portB: "127.0.0.1"})
+	resp = requester.Do(&request.Request{Method: method.GET, URL: "http://svc.example.com:" + portB + "/"})
+	if resp.Error != nil || string(resp.ResponseBody) != namedBody("b", "svc.example.com:"+portB, "/") {
+		t.Errorf("只有 IP 的覆盖应使用请求端口: %v %s", resp.Error, resp.ResponseBody)
+	}
+}
+
+// TestBatchDNSCache 批量请求共享一次 DNS 查询，缓存在 TTL 后过期，解析失败报告为 DNS 错误
+func TestBatchDNSCache(t *testing.T) {
+	server := newNamedServer("s", nil)
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	upstream := &countingResolver{}
+	dnsCache := resolver.NewCache(200*time.Millisecond, upstream)
+	batchRequester := reqbatch.NewBatchRequester(false)
+	batchRequester.SetResolver(dnsCache)
+
+	requests := make([]*request.Request, 20)
+	for i := range requests {
+		requests[i] = &request.Request{Method: method.GET, URL: "http://svc.test:" + port + "/"}
+	}
+	for _, resp := range batchRequester.Do(requests) {
+		if resp.Error != nil || string(resp.ResponseBody) != namedBody("s", "svc.test:"+port, "/") {
+			t.Fatalf("请求错误: %v %s", resp.Error, resp.ResponseBody)
+		}
+	}
+	if n := upstream.lookups.Load(); n != 1 {
+		t.Errorf("并发请求应共享一次查询, 实际查询 %d 次", n)
+	}
+
+	// TTL 过期后重新查询
+	time.Sleep(250 * time.Millisecond)
+	if addrs, err := dnsCache.LookupHost(context.Background(), "svc.test"); err != nil || addrs[0] != "127.0.0.1" {
+		t.Fatalf("查询错误: %v %v", addrs, err)
+	}
+	if n := upstream.lookups.Load(); n != 2 {
+		t.Errorf("TTL 过期后应重新查询, 实际查询 %d 次", n)
+	}
+
+	// 解析失败报告为 DNS 错误
+	responses := batchRequester.Do([]*request.Request{{Method: method.GET, URL: "http://missing.example:" + port + "/"}})
+	if err := responses[0].Error; !errors.Is(err, reqerr.ErrDNS) {
+		t.Errorf("期望 DNS 错误, 实际: %v", err)
+	}
+}
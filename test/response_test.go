@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// TestSingleResponseMetadata 响应头、Cookie、协议与最终地址
+func TestSingleResponseMetadata(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/items", http.StatusFound)
+	})
+	mux.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "99")
+		w.Header().Add("Link", `</items?page=2>; rel="next", </items?page=5>; rel="last"`)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		_, _ = w.Write([]byte("items"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL + "/old"})
+	if resp.Error != nil {
+		t.Fatalf("请求错误: %v", resp.Error)
+	}
+
+	if resp.FinalURL != server.URL+"/items" {
+		t.Errorf("最终地址错误: %s", resp.FinalURL)
+	}
+	if resp.Proto != "HTTP/1.1" || resp.IsHTTP2() {
+		t.Errorf("协议错误: %s", resp.Proto)
+	}
+	if resp.ETag() != `"v1"` {
+		t.Errorf("ETag 错误: %s", resp.ETag())
+	}
+	if cookie := resp.Cookie("session"); cookie == nil || cookie.Value != "abc" {
+		t.Errorf("Cookie 错误: %v", cookie)
+	}
+	if resp.Link("next") != "/items?page=2" || resp.Link("last") != "/items?page=5" {
+		t.Errorf("Link 错误: %v", resp.Links())
+	}
+	if rateLimit := resp.RateLimit(); rateLimit.Limit != 100 || rateLimit.Remaining != 99 {
+		t.Errorf("限流信息错误: %+v", rateLimit)
+	}
+	if resp.ContentLength != int64(len("items")) {
+		t.Errorf("Content-Length 错误: %d", resp.ContentLength)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/reqstream"
+	"github.com/GoEnthusiast/httpreq/retry"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// newFlakyServer 创建一个前 failures 次返回 503 的测试服务器，并校验每次收到的请求体
+func newFlakyServer(t *testing.T, failures int32, wantBody string) *httptest.Server {
+	var calls int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != wantBody {
+			t.Errorf("请求体错误: %q", body)
+		}
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+}
+
+// TestSingleRetryRebuildsBody 允许重试非幂等请求时，每次都重新发送请求体
+func TestSingleRetryRebuildsBody(t *testing.T) {
+	server := newFlakyServer(t, 2, "hello")
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	policy := retry.NewPolicy(3, retry.NewExponentialBackoff(10*time.Millisecond, 50*time.Millisecond))
+	policy.RetryNonIdempotent = true
+	requester.SetRetryPolicy(policy)
+
+	resp := requester.Do(&request.Request{
+		Method:      method.POST,
+		URL:         server.URL,
+		Body:        "hello",
+		ContentType: method.ContentTypeText,
+	})
+	if resp.Error != nil {
+		t.Fatalf("请求错误: %v", resp.Error)
+	}
+	if resp.ResponseStatusCode != http.StatusOK || len(resp.Attempts) != 3 {
+		t.Fatalf("期望第 3 次尝试成功, 实际状态码 %d, 尝试 %d 次", resp.ResponseStatusCode, len(resp.Attempts))
+	}
+	for _, attempt := range resp.Attempts {
+		t.Logf("第 %d 次尝试: 状态码 %d, 耗时 %s, 错误 %v\n", attempt.Number, attempt.StatusCode, attempt.Duration, attempt.Error)
+	}
+}
+
+// TestSingleRetrySkipsPost 默认条件不重试 POST，幂等方法仍会重试
+func TestSingleRetrySkipsPost(t *testing.T) {
+	server := newFlakyServer(t, 2, "hello")
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetRetryPolicy(retry.NewPolicy(3, retry.NewConstantBackoff(time.Millisecond)))
+
+	resp := requester.Do(&request.Request{Method: method.POST, URL: server.URL, Body: "hello", ContentType: method.ContentTypeText})
+	if resp.ResponseStatusCode != http.StatusServiceUnavailable || len(resp.Attempts) != 1 {
+		t.Fatalf("POST 应只发送一次, 实际状态码 %d, 尝试 %d 次", resp.ResponseStatusCode, len(resp.Attempts))
+	}
+	resp = requester.Do(&request.Request{Method: method.PUT, URL: server.URL, Body: "hello", ContentType: method.ContentTypeText})
+	if resp.ResponseStatusCode != http.StatusOK || len(resp.Attempts) != 2 {
+		t.Errorf("PUT 请求失败: 状态码 %d, 尝试 %d 次", resp.ResponseStatusCode, len(resp.Attempts))
+	}
+}
+
+// TestStreamRetryPerRequestPolicy 请求级别的重试策略覆盖请求器默认值
+func TestStreamRetryPerRequestPolicy(t *testing.T) {
+	server := newFlakyServer(t, 5, "")
+	defer server.Close()
+
+	streamRequester := reqstream.NewStreamRequester(false, 1)
+	streamRequester.SetRetryPolicy(retry.NewPolicy(10, nil))
+
+	streamRequester.Do(&request.Request{
+		Method: method.GET,
+		URL:    server.URL,
+		Retry:  retry.NewPolicy(2, retry.NewConstantBackoff(time.Millisecond), retry.RetryOnStatus(http.StatusServiceUnavailable)),
+	})
+
+	resp := <-streamRequester.ResponseCh()
+	if resp.ResponseStatusCode != http.StatusServiceUnavailable || len(resp.Attempts) != 2 {
+		t.Fatalf("期望尝试 2 次后放弃, 实际状态码 %d, 尝试 %d 次", resp.ResponseStatusCode, len(resp.Attempts))
+	}
+}
+
+// TestExponentialBackoffMultiplier 增长系数为零时默认为 2，显式设置的 1 保持不变，小于 1 的值按 1 处理
+func TestExponentialBackoffMultiplier(t *testing.T) {
+	for multiplier, want := range map[float64]time.Duration{0: 40 * time.Millisecond, 1: 10 * time.Millisecond, 0.5: 10 * time.Millisecond, 3: 90 * time.Millisecond} {
+		backoff := &retry.ExponentialBackoff{Initial: 10 * time.Millisecond, Multiplier: multiplier}
+		if delay := backoff.Delay(3); delay != want {
+			t.Errorf("增长系数 %v: 期望 %v, 实际 %v", multiplier, want, delay)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/session"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// newLoginServer 创建一个登录后通过 Cookie 识别用户的测试服务器
+func newLoginServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "user-1", Path: "/api", MaxAge: 3600, HttpOnly: true})
+		http.Redirect(w, r, "/api/profile", http.StatusFound)
+	})
+	mux.HandleFunc("/api/profile", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil || r.Header.Get("X-Client") != "httpreq" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(cookie.Value))
+	})
+	return httptest.NewServer(mux)
+}
+
+// TestSessionLoginFlow 会话在多次请求之间共享 Cookie、默认请求头和基础地址
+func TestSessionLoginFlow(t *testing.T) {
+	server := newLoginServer()
+	defer server.Close()
+
+	sess := session.NewSession(false, nil)
+	if err := sess.SetBaseURL(server.URL + "/api"); err != nil {
+		t.Fatal(err)
+	}
+	sess.SetHeader("X-Client", "httpreq")
+
+	// 登录响应设置的 Cookie 在重定向中即被使用
+	resp := sess.Do(&request.Request{Method: method.POST, URL: "/login"})
+	if resp.Error != nil || string(resp.ResponseBody) != "user-1" {
+		t.Fatalf("登录失败: %d %v", resp.ResponseStatusCode, resp.Error)
+	}
+
+	// 后续请求自动携带 Cookie
+	req := &request.Request{Method: method.GET, URL: "profile"}
+	resp = sess.Do(req)
+	if string(resp.ResponseBody) != "user-1" {
+		t.Fatalf("期望已登录, 实际: %d", resp.ResponseStatusCode)
+	}
+	if resp.Request != req || req.Header != nil || req.URL != "profile" {
+		t.Errorf("原始请求被修改: %+v", req)
+	}
+	if cookies := sess.Jar().All(); len(cookies) != 1 || !cookies[0].HttpOnly || cookies[0].Path != "/api" {
+		t.Errorf("Cookie 容器内容错误: %+v", cookies)
+	}
+}
+
+// TestSessionJarPersistence Cookie 以 JSON 和 Netscape 格式保存后可以重新加载
+func TestSessionJarPersistence(t *testing.T) {
+	server := newLoginServer()
+	defer server.Close()
+
+	for name, format := range map[string]session.Format{"cookies.json": session.FormatJSON, "cookies.txt": session.FormatNetscape} {
+		filename := filepath.Join(t.TempDir(), name)
+		jar, err := session.NewFileJar(filename, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sess := session.NewSession(false, jar)
+		sess.SetHeader("X-Client", "httpreq")
+		sess.Do(&request.Request{Method: method.POST, URL: server.URL + "/api/login"})
+		if err = sess.Save(); err != nil {
+			t.Fatalf("%s: 保存失败: %v", name, err)
+		}
+
+		// 新会话从文件中恢复登录状态
+		jar, err = session.NewFileJar(filename, format)
+		if err != nil {
+			t.Fatalf("%s: 加载失败: %v", name, err)
+		}
+		sess = session.NewSession(false, jar)
+		sess.SetHeader("X-Client", "httpreq")
+		resp := sess.Do(&request.Request{Method: method.GET, URL: server.URL + "/api/profile"})
+		if string(resp.ResponseBody) != "user-1" {
+			t.Errorf("%s: 期望恢复登录状态, 实际: %d %+v", name, resp.ResponseStatusCode, jar.All())
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/signer"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// TestSigV4TestSuite 使用 AWS SigV4 官方测试用例校验签名
+func TestSigV4TestSuite(t *testing.T) {
+	sigV4 := signer.NewSigV4(signer.SigV4Config{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "service",
+		Now:             func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) },
+	})
+	cases := []struct {
+		name      string
+		url       string
+		signature string
+	}{
+		{"get-vanilla", "https://example.amazonaws.com/", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"get-vanilla-query-order-key-case", "https://example.amazonaws.com/?Param2=value2&Param1=value1", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(http.MethodGet, c.url, nil)
+		if err := sigV4.Sign(req, nil); err != nil {
+			t.Fatal(err)
+		}
+		want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=" + c.signature
+		if got := req.Header.Get("Authorization"); got != want {
+			t.Errorf("%s: 签名错误\n期望: %s\n实际: %s", c.name, want, got)
+		}
+	}
+}
+
+// TestSingleSigV4S3Upload S3 上传请求签名覆盖最终的请求体
+func TestSingleSigV4S3Upload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/us-east-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token,") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+			return
+		}
+		_, _ = w.Write([]byte(r.URL.EscapedPath()))
+	}))
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetSigner(signer.NewSigV4(signer.SigV4Config{
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "SECRET",
+		SessionToken:    "SESSION",
+		Region:          "us-east-1",
+		Service:         "s3",
+	}))
+	resp := requester.Do(&request.Request{
+		Method:      method.PUT,
+		URL:         server.URL + "/bucket/{key}",
+		PathParams:  map[string]string{"key": "a b!.txt"},
+		ContentType: method.ContentTypeJSON,
+		Body:        map[string]string{"hello": "world"},
+	})
+	if resp.Error != nil || resp.ResponseStatusCode != http.StatusOK {
+		t.Fatalf("签名校验失败: %d %s %v", resp.ResponseStatusCode, resp.ResponseBody, resp.Error)
+	}
+	if string(resp.ResponseBody) != "/bucket/a%20b%21.txt" {
+		t.Errorf("路径编码错误: %s", resp.ResponseBody)
+	}
+}
+
+// TestBatchHMACSigner 服务端使用 signer.StringToSign 校验批量请求的 HMAC 签名
+func TestBatchHMACSigner(t *testing.T) {
+	secret := []byte("shared-secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signer.StringToSign(r, body, r.Header.Get("X-Timestamp"), []string{"Host", "X-Request-Id"})))
+		want := "HMAC-SHA256 KeyId=key-1,SignedHeaders=host;x-request-id,Signature=" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		if r.Header.Get("Authorization") != want {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	batchRequester := reqbatch.NewBatchRequester(false)
+	batchRequester.SetSigner(signer.NewHMAC(signer.HMACConfig{
+		KeyID:         "key-1",
+		Secret:        secret,
+		SignedHeaders: []string{"Host", "X-Request-Id"},
+	}))
+	requests := []*request.Request{
+		{Method: method.GET, URL: server.URL + "/items", Query: map[string]string{"q": "a b"}, Header: http.Header{"X-Request-Id": {"1"}}},
+		{Method: method.POST, URL: server.URL + "/items", ContentType: method.ContentTypeForm, Body: map[string]string{"name": "x"}, Header: http.Header{"X-Request-Id": {"2"}}},
+	}
+	for _, resp := range batchRequester.Do(requests) {
+		if resp.Error != nil || resp.ResponseStatusCode != http.StatusOK {
+			t.Errorf("HMAC 签名校验失败: %d %v", resp.ResponseStatusCode, resp.Error)
+		}
+	}
+}
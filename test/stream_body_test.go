@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/retry"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// newDownloadServer 创建一个返回 size 字节数据的下载服务器，/missing 返回 404
+func newDownloadServer(size int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		_, _ = io.Copy(w, strings.NewReader(strings.Repeat("x", size)))
+	}))
+}
+
+// TestSingleStreamBody 流式读取响应体，读取完毕后记录耗时
+func TestSingleStreamBody(t *testing.T) {
+	server := newDownloadServer(1 << 20)
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL, StreamBody: true})
+	if resp.Error != nil {
+		t.Fatalf("请求错误: %v", resp.Error)
+	}
+	if resp.ResponseBody != nil || resp.BodyReader == nil {
+		t.Fatalf("期望流式响应体")
+	}
+
+	n, err := io.Copy(io.Discard, resp.BodyReader)
+	_ = resp.BodyReader.Close()
+	if err != nil || n != 1<<20 || resp.BodySize != n {
+		t.Fatalf("读取响应体错误: %v, %d, %d", err, n, resp.BodySize)
+	}
+	if resp.EndTime.Before(resp.StartTime) {
+		t.Fatalf("耗时记录错误")
+	}
+	if len(resp.Attempts) != 1 || resp.Attempts[0].Duration <= 0 {
+		t.Fatalf("尝试耗时记录错误: %+v", resp.Attempts)
+	}
+	t.Logf("请求耗时: %.4fs\n", resp.Duration)
+}
+
+// TestSingleBodyWriterNoRetry 已写入 BodyWriter 的响应体读取中途失败时不重试，以免重复写入
+func TestSingleBodyWriterNoRetry(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Length", "100")
+		_, _ = w.Write([]byte("part"))
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}))
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetRetryPolicy(retry.NewPolicy(3, retry.NewConstantBackoff(time.Millisecond)))
+	buf := &bytes.Buffer{}
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL, BodyWriter: buf})
+	if !errors.Is(resp.Error, reqerr.ErrBodyRead) || hits.Load() != 1 || len(resp.Attempts) != 1 {
+		t.Fatalf("期望读取失败且不重试: %v 服务器收到 %d 个请求, 尝试 %d 次", resp.Error, hits.Load(), len(resp.Attempts))
+	}
+	if buf.String() != "part" || resp.BodySize != 4 {
+		t.Errorf("写入器内容错误: %q, BodySize=%d", buf.String(), resp.BodySize)
+	}
+}
+
+// TestBatchDownloadToSink 批量下载到文件与写入器，失败响应体仍读入内存
+func TestBatchDownloadToSink(t *testing.T) {
+	server := newDownloadServer(64 << 10)
+	defer server.Close()
+
+	outputFile := filepath.Join(t.TempDir(), "download.bin")
+	buf := &bytes.Buffer{}
+	batchRequester := reqbatch.NewBatchRequester(false)
+	responses := batchRequester.Do([]*request.Request{
+		{Method: method.GET, URL: server.URL, OutputFile: outputFile},
+		{Method: method.GET, URL: server.URL, BodyWriter: buf},
+		{Method: method.GET, URL: server.URL + "/missing", BodyWriter: io.Discard},
+	})
+
+	for _, resp := range responses {
+		if resp.Error != nil {
+			t.Fatalf("请求错误: %v", resp.Error)
+		}
+		if resp.ResponseStatusCode == http.StatusNotFound {
+			if !strings.Contains(string(resp.ResponseBody), "not found") {
+				t.Fatalf("失败响应体应读入内存: %q", resp.ResponseBody)
+			}
+			continue
+		}
+		if resp.ResponseBody != nil || resp.BodySize != 64<<10 {
+			t.Fatalf("下载大小错误: %d", resp.BodySize)
+		}
+	}
+
+	if info, err := os.Stat(outputFile); err != nil || info.Size() != 64<<10 {
+		t.Fatalf("输出文件错误: %v", err)
+	}
+	if buf.Len() != 64<<10 {
+		t.Fatalf("写入器大小错误: %d", buf.Len())
+	}
+}
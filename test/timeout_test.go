@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/core"
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// TestBatchPerRequestTimeout 并发请求的超时时间互不影响
+func TestBatchPerRequestTimeout(t *testing.T) {
+	server := newSlowServer(300 * time.Millisecond)
+	defer server.Close()
+
+	batchRequester := reqbatch.NewBatchRequester(false)
+	requests := []*request.Request{
+		{Method: method.GET, URL: server.URL, Timeout: 50 * time.Millisecond, Meta: map[string]interface{}{"timeout": true}},
+		{Method: method.GET, URL: server.URL, ConnectTimeout: time.Second, Meta: map[string]interface{}{"timeout": false}},
+		{Method: method.GET, URL: server.URL, Timeout: 2 * time.Second, Meta: map[string]interface{}{"timeout": false}},
+	}
+
+	for _, resp := range batchRequester.Do(requests) {
+		expectTimeout := resp.Request.Meta["timeout"].(bool)
+		if expectTimeout && !errors.Is(resp.Error, core.ErrRequestTimeout) {
+			t.Fatalf("期望超时错误, 实际: %v", resp.Error)
+		}
+		if !expectTimeout && resp.Error != nil {
+			t.Fatalf("请求错误: %v", resp.Error)
+		}
+	}
+}
+
+// TestSingleDefaultAndHeaderTimeout 请求器默认超时与响应头超时
+func TestSingleDefaultAndHeaderTimeout(t *testing.T) {
+	server := newSlowServer(300 * time.Millisecond)
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetTimeout(50 * time.Millisecond)
+
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL})
+	if !errors.Is(resp.Error, core.ErrRequestTimeout) {
+		t.Fatalf("期望默认超时生效, 实际: %v", resp.Error)
+	}
+
+	// 请求自身的超时时间覆盖请求器默认值
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL, Timeout: 2 * time.Second})
+	if resp.Error != nil {
+		t.Fatalf("请求错误: %v", resp.Error)
+	}
+
+	resp = requester.Do(&request.Request{
+		Method:        method.GET,
+		URL:           server.URL,
+		Timeout:       2 * time.Second,
+		HeaderTimeout: 50 * time.Millisecond,
+	})
+	if !errors.Is(resp.Error, core.ErrRequestTimeout) {
+		t.Fatalf("期望响应头超时, 实际: %v", resp.Error)
+	}
+	t.Logf("请求错误: %v\n", resp.Error)
+}
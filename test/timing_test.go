@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/reqbatch"
+	"github.com/GoEnthusiast/httpreq/reqsingle"
+	"github.com/GoEnthusiast/httpreq/types/request"
+)
+
+// TestSinglePhaseTiming 记录连接、TLS、首字节和响应体传输耗时，第二次请求复用连接
+func TestSinglePhaseTiming(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	requester := reqsingle.NewSingleRequester(false)
+	requester.SetTransport(server.Client().Transport.(*http.Transport).Clone())
+
+	resp := requester.Do(&request.Request{Method: method.GET, URL: server.URL})
+	if resp.Error != nil {
+		t.Fatalf("请求错误: %v", resp.Error)
+	}
+	timing := resp.Timing
+	t.Logf("阶段耗时: %+v\n", timing)
+	if timing.TCPConnect <= 0 || timing.TLSHandshake <= 0 {
+		t.Errorf("缺少连接或 TLS 耗时: %+v", timing)
+	}
+	if timing.TimeToFirstByte < 50*time.Millisecond || timing.BodyTransfer < 40*time.Millisecond {
+		t.Errorf("首字节或响应体传输耗时错误: %+v", timing)
+	}
+	if timing.ConnReused || timing.RemoteAddr != server.Listener.Addr().String() {
+		t.Errorf("连接信息错误: %+v", timing)
+	}
+
+	resp = requester.Do(&request.Request{Method: method.GET, URL: server.URL})
+	if !resp.Timing.ConnReused || resp.Timing.TCPConnect != 0 {
+		t.Errorf("期望复用连接: %+v", resp.Timing)
+	}
+}
+
+// TestBatchPhaseTiming 批量请求的每个响应都带有阶段耗时
+func TestBatchPhaseTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	batchRequester := reqbatch.NewBatchRequester(false)
+	requests := []*request.Request{}
+	for i := 0; i < 3; i++ {
+		requests = append(requests, &request.Request{Method: method.GET, URL: server.URL})
+	}
+	for _, resp := range batchRequester.Do(requests) {
+		if resp.Error != nil {
+			t.Fatalf("请求错误: %v", resp.Error)
+		}
+		if resp.Timing.TimeToFirstByte <= 0 || resp.Timing.RemoteAddr == "" {
+			t.Errorf("阶段耗时缺失: %+v", resp.Timing)
+		}
+	}
+}
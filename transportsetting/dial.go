@@ -0,0 +1,104 @@
+package transportsetting
+
+import (
+	"context"
+	"net"
+	"strings"
+)
+
+// DialContextFunc dials a connection to addr on network, the signature of http.Transport.DialContext
+// DialContextFunc 在 network 上拨号连接 addr，与 http.Transport.DialContext 的签名相同
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// unixSocketContextKey is the context key for the per-request Unix socket path
+// unixSocketContextKey 是单请求 Unix 套接字路径的上下文键
+type unixSocketContextKey struct{}
+
+// unixURLHost is the host of the HTTP URL a unix:// URL is rewritten to
+// unixURLHost 是 unix:// 地址改写后的 HTTP 地址所使用的主机
+const unixURLHost = "localhost"
+
+// ParseUnixURL splits a "unix:///var/run/x.sock:/path?query" URL into the socket path and the HTTP URL sent over it,
+// "http://localhost/path?query". ok is false when rawURL does not use the unix scheme.
+// ParseUnixURL 将 "unix:///var/run/x.sock:/path?query" 地址拆分为套接字路径和通过该套接字发送的 HTTP 地址
+// "http://localhost/path?query"。rawURL 不使用 unix 协议时 ok 为 false。
+func ParseUnixURL(rawURL string) (socketPath, httpURL string, ok bool) {
+	rest, found := strings.CutPrefix(rawURL, "unix://")
+	if !found {
+		return "", "", false
+	}
+	socketPath, path, found := strings.Cut(rest, ":")
+	if !found || path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return socketPath, "http://" + unixURLHost + path, true
+}
+
+// WithUnixSocket returns a copy of ctx sending the request over the Unix socket at socketPath,
+// whatever the host of its URL. An empty socketPath leaves ctx unchanged.
+// WithUnixSocket 返回一个 ctx 副本，使请求无论地址中的主机是什么都通过 socketPath 处的 Unix 套接字发送。
+// socketPath 为空时 ctx 保持不变。
+func WithUnixSocket(ctx context.Context, socketPath string) context.Context {
+	if socketPath == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, unixSocketContextKey{}, socketPath)
+}
+
+// SetDialContext sets the hook dialing every TCP connection, including Unix sockets, instead of the default dialer.
+// Connect timeouts, resolve overrides and custom resolvers still apply; nil restores the default dialer.
+// HTTP/3 connections are dialed over QUIC and do not go through the hook.
+// SetDialContext 设置拨号所有 TCP 连接（包括 Unix 套接字）的钩子，代替默认拨号器。
+// 连接超时、解析覆盖和自定义解析器仍然生效；nil 表示恢复默认拨号器。HTTP/3 连接通过 QUIC 拨号，不经过该钩子。
+func (c *TransportSetting) SetDialContext(dial DialContextFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.dialContext = dial
+	c.resetPoolLocked()
+}
+
+// SetUnixSocket sends requests to host ("host:port", or "host" for any port) over the Unix socket at socketPath,
+// an empty socketPath removes the mapping
+// SetUnixSocket 使发往 host（"host:port"，或表示任意端口的 "host"）的请求通过 socketPath 处的 Unix 套接字发送，
+// socketPath 为空表示移除该映射
+func (c *TransportSetting) SetUnixSocket(host, socketPath string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if socketPath == "" {
+		delete(c.unixSockets, host)
+	} else {
+		if c.unixSockets == nil {
+			c.unixSockets = make(map[string]string)
+		}
+		c.unixSockets[host] = socketPath
+	}
+	c.resetPoolLocked()
+}
+
+// socketDial dials the per-request Unix socket, or the socket mapped to the host of addr, with dial;
+// other addresses are dialed with next
+// socketDial 使用 dial 拨号单请求 Unix 套接字或 addr 主机映射的套接字；其他地址使用 next 拨号
+func socketDial(dial, next DialContextFunc, socketPath string, sockets map[string]string) DialContextFunc {
+	if socketPath == "" && len(sockets) == 0 {
+		return next
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if socketPath != "" {
+			return dial(ctx, "unix", socketPath)
+		}
+		if path, ok := sockets[addr]; ok {
+			return dial(ctx, "unix", path)
+		}
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			if path, ok := sockets[host]; ok {
+				return dial(ctx, "unix", path)
+			}
+		}
+		return next(ctx, network, addr)
+	}
+}
@@ -0,0 +1,440 @@
+package transportsetting
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoEnthusiast/httpreq/resolver"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+const (
+	defaultAltSvcMaxAge  = 24 * time.Hour   // Lifetime of an Alt-Svc entry without ma / 没有 ma 参数的 Alt-Svc 条目的有效期
+	http3BrokenDuration  = 5 * time.Minute  // Time HTTP/3 is not tried again for an origin it failed on / HTTP/3 失败的源在此时间内不再尝试 HTTP/3
+	maxHTTP3Entries      = 4096             // Alt-Svc or broken origin entries kept before expired ones are swept / 清理过期条目前保留的 Alt-Svc 或失败源条目数
+	defaultQUICHandshake = 10 * time.Second // QUIC handshake timeout when no connect timeout is set / 未设置连接超时时的 QUIC 握手超时时间
+)
+
+// HTTP3Mode selects when requests are sent over HTTP/3 (QUIC). Only direct https requests use HTTP/3,
+// requests through a proxy or a Unix socket always use TCP.
+// HTTP3Mode 选择何时通过 HTTP/3（QUIC）发送请求。只有直连的 https 请求使用 HTTP/3，经代理或 Unix 套接字的请求总是使用 TCP。
+type HTTP3Mode int
+
+const (
+	HTTP3Off       HTTP3Mode = iota // HTTP/1.1 and HTTP/2 over TCP only / 只通过 TCP 使用 HTTP/1.1 和 HTTP/2
+	HTTP3Only                       // Every https request over HTTP/3, no fallback / 所有 https 请求都使用 HTTP/3，不回退
+	HTTP3Preferred                  // HTTP/3 first, TCP when the QUIC connection cannot be established / 优先使用 HTTP/3，无法建立 QUIC 连接时使用 TCP
+	HTTP3AltSvc                     // TCP until the origin advertises h3 with Alt-Svc, then HTTP/3 with fallback to TCP / 使用 TCP 直到源通过 Alt-Svc 声明 h3，之后使用 HTTP/3 并可回退到 TCP
+)
+
+// altService is an HTTP/3 alternative service advertised by an origin
+// altService 是源声明的 HTTP/3 替代服务
+type altService struct {
+	authority string    // host:port to dial / 要拨号的 host:port
+	expires   time.Time // End of the advertised lifetime / 声明的有效期结束时间
+}
+
+// http3Transport is an HTTP/3 transport with the UDP socket it dials from
+// http3Transport 是一个 HTTP/3 传输层及其拨号使用的 UDP 套接字
+type http3Transport struct {
+	transport *http3.Transport // HTTP/3 transport / HTTP/3 传输层
+	quic      *quic.Transport  // QUIC transport, created on first dial / QUIC 传输层，首次拨号时创建
+	mu        sync.Mutex       // Guards quic / 保护 quic
+	lastUsed  time.Time        // Last time the transport was used / 最后使用时间
+	inFlight  int              // Requests in flight, response bodies not yet closed included / 进行中的请求数（包括尚未关闭的响应体）
+	dropped   bool             // Whether it was removed from the HTTP/3 pool while in use / 是否在使用中被移出 HTTP/3 传输层池
+}
+
+// quicDialError marks a failure to establish the QUIC connection, before any request was sent
+// quicDialError 标记建立 QUIC 连接失败，此时尚未发送任何请求
+type quicDialError struct {
+	err error // Dial error / 拨号错误
+}
+
+func (e *quicDialError) Error() string { return "quic dial error: " + e.err.Error() }
+func (e *quicDialError) Unwrap() error { return e.err }
+
+// SetHTTP3 selects when requests are sent over HTTP/3
+// SetHTTP3 选择何时通过 HTTP/3 发送请求
+func (c *TransportSetting) SetHTTP3(mode HTTP3Mode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.http3Mode = mode
+	c.altServices = make(map[string]altService)
+	c.http3Broken = make(map[string]time.Time)
+	c.resetPoolLocked()
+}
+
+// tryHTTP3 sends req over HTTP/3 when the mode asks for it. A non-nil fallback request, req itself or a copy with a
+// fresh body, has to be sent over TCP instead because HTTP/3 does not apply or its connection could not be established.
+// tryHTTP3 在模式要求时通过 HTTP/3 发送 req。fallback 请求（req 本身或带有新请求体的副本）不为 nil 时，
+// 由于不适用 HTTP/3 或无法建立其连接，需要改为通过 TCP 发送。
+func (c *TransportSetting) tryHTTP3(req *http.Request, overrides map[string]string) (resp *http.Response, fallback *http.Request, err error) {
+	if req.URL.Scheme != "https" {
+		return nil, req, nil
+	}
+	origin := canonicalAddr(req.URL.Host)
+
+	c.mu.Lock()
+	mode, now := c.http3Mode, time.Now()
+	use := false
+	if _, ok := c.unixSockets[origin]; ok {
+		mode = HTTP3Off
+	} else if _, ok := c.unixSockets[req.URL.Hostname()]; ok {
+		mode = HTTP3Off
+	}
+	switch mode {
+	case HTTP3Only:
+		use = true
+	case HTTP3Preferred:
+		use = !now.Before(c.http3Broken[origin])
+	case HTTP3AltSvc:
+		alt, ok := c.altServices[origin]
+		use = ok && now.Before(alt.expires)
+	}
+	var transport *http3Transport
+	if use {
+		transport = c.http3TransportLocked(overrides)
+	}
+	c.mu.Unlock()
+	if !use {
+		return nil, req, nil
+	}
+
+	resp, err = transport.transport.RoundTrip(req)
+	release := func() { c.releaseHTTP3(transport) }
+	if err != nil {
+		release()
+	} else {
+		resp.Body = &trackedBody{ReadCloser: resp.Body, release: release}
+	}
+	var dialErr *quicDialError
+	if err == nil || mode == HTTP3Only || !errors.As(err, &dialErr) || req.Context().Err() != nil {
+		return resp, nil, err
+	}
+
+	// Remember the failure and send the request over TCP instead
+	// 记录失败并改为通过 TCP 发送请求
+	c.mu.Lock()
+	now = time.Now()
+	c.sweepHTTP3Locked(now)
+	c.http3Broken[origin] = now.Add(http3BrokenDuration)
+	delete(c.altServices, origin)
+	c.mu.Unlock()
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, req, nil
+	}
+	if req.GetBody == nil {
+		return nil, nil, err
+	}
+	body, bodyErr := req.GetBody()
+	if bodyErr != nil {
+		return nil, nil, err
+	}
+	fallback = req.Clone(req.Context())
+	fallback.Body = body
+	return nil, fallback, nil
+}
+
+// learnAltSvc records the h3 alternative service advertised by a TCP response of an https origin
+// learnAltSvc 记录 https 源的 TCP 响应所声明的 h3 替代服务
+func (c *TransportSetting) learnAltSvc(req *http.Request, resp *http.Response) {
+	if req.URL.Scheme != "https" {
+		return
+	}
+	values := resp.Header.Values("Alt-Svc")
+	if len(values) == 0 {
+		return
+	}
+	origin := canonicalAddr(req.URL.Host)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.http3Mode != HTTP3AltSvc || time.Now().Before(c.http3Broken[origin]) {
+		return
+	}
+	alt, ok, clear := parseAltSvc(values, origin)
+	switch {
+	case clear:
+		delete(c.altServices, origin)
+	case ok:
+		c.sweepHTTP3Locked(time.Now())
+		c.altServices[origin] = alt
+	}
+}
+
+// sweepHTTP3Locked makes room in the Alt-Svc and broken origin maps once they hold maxHTTP3Entries entries, dropping
+// expired entries first and arbitrary ones while still full, so crawlers touching many hosts use bounded memory
+// sweepHTTP3Locked 在 Alt-Svc 和失败源映射达到 maxHTTP3Entries 个条目时腾出空间，先清理过期条目，仍然已满时任意删除条目，
+// 使访问大量主机的爬虫占用有限的内存
+func (c *TransportSetting) sweepHTTP3Locked(now time.Time) {
+	if len(c.altServices) >= maxHTTP3Entries {
+		for key, alt := range c.altServices {
+			if !now.Before(alt.expires) {
+				delete(c.altServices, key)
+			}
+		}
+		for key := range c.altServices {
+			if len(c.altServices) < maxHTTP3Entries {
+				break
+			}
+			delete(c.altServices, key)
+		}
+	}
+	if len(c.http3Broken) >= maxHTTP3Entries {
+		for key, until := range c.http3Broken {
+			if !now.Before(until) {
+				delete(c.http3Broken, key)
+			}
+		}
+		for key := range c.http3Broken {
+			if len(c.http3Broken) < maxHTTP3Entries {
+				break
+			}
+			delete(c.http3Broken, key)
+		}
+	}
+}
+
+// http3TransportLocked acquires the HTTP/3 transport for the per-request overrides, creating it if necessary;
+// it must be released with releaseHTTP3
+// http3TransportLocked 获取单请求覆盖对应的 HTTP/3 传输层，必要时创建；使用后必须通过 releaseHTTP3 释放
+func (c *TransportSetting) http3TransportLocked(overrides map[string]string) *http3Transport {
+	key := overridesKey(overrides)
+	now := time.Now()
+	if t, ok := c.http3Pool[key]; ok {
+		t.lastUsed = now
+		t.inFlight++
+		return t
+	}
+	if len(c.http3Pool) >= c.maxProxyTransports {
+		c.evictOldestHTTP3Locked()
+	}
+
+	t := &http3Transport{lastUsed: now, inFlight: 1}
+	t.transport = &http3.Transport{
+		TLSClientConfig: c.transport.TLSClientConfig.Clone(),
+		Dial:            c.quicDialLocked(t, overrides),
+	}
+	c.http3Pool[key] = t
+	return t
+}
+
+// quicDialLocked returns the QUIC dial function of t: it dials the advertised alternative service, applies the resolve
+// overrides (per-request ones first) and the custom resolver, and bounds the handshake with the connect timeout
+// quicDialLocked 返回 t 的 QUIC 拨号函数：拨号声明的替代服务，应用解析覆盖（单请求覆盖优先）和自定义解析器，
+// 并以连接超时限制握手时间
+func (c *TransportSetting) quicDialLocked(t *http3Transport, requestOverrides map[string]string) func(ctx context.Context, addr string, tlsConf *tls.Config, conf *quic.Config) (*quic.Conn, error) {
+	globalOverrides, r, defaultTimeout := c.resolveOverrides, c.resolver, c.connectTimeout
+	return func(ctx context.Context, addr string, tlsConf *tls.Config, conf *quic.Config) (*quic.Conn, error) {
+		c.mu.Lock()
+		if alt, ok := c.altServices[addr]; ok {
+			addr = alt.authority
+		}
+		c.mu.Unlock()
+
+		timeout, ok := ctx.Value(connectTimeoutContextKey{}).(time.Duration)
+		if !ok {
+			timeout = defaultTimeout
+		}
+		if timeout <= 0 {
+			timeout = defaultQUICHandshake
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		addrs, err := quicAddrs(ctx, addr, requestOverrides, globalOverrides, r)
+		if err != nil {
+			return nil, &quicDialError{err: err}
+		}
+		qt, err := t.quicTransport()
+		if err != nil {
+			return nil, &quicDialError{err: err}
+		}
+		for _, udpAddr := range addrs {
+			var conn *quic.Conn
+			if conn, err = qt.DialEarly(ctx, udpAddr, tlsConf, conf); err == nil {
+				return conn, nil
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, &quicDialError{err: err}
+	}
+}
+
+// quicTransport returns the QUIC transport of t, listening on a UDP socket on first use
+// quicTransport 返回 t 的 QUIC 传输层，首次使用时监听一个 UDP 套接字
+func (t *http3Transport) quicTransport() (*quic.Transport, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.quic == nil {
+		conn, err := net.ListenUDP("udp", nil)
+		if err != nil {
+			return nil, err
+		}
+		t.quic = &quic.Transport{Conn: conn}
+	}
+	return t.quic, nil
+}
+
+// close closes the connections and the UDP socket of t
+// close 关闭 t 的连接和 UDP 套接字
+func (t *http3Transport) close() {
+	_ = t.transport.Close()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.quic != nil {
+		_ = t.quic.Close()
+		t.quic = nil
+	}
+}
+
+// releaseHTTP3 marks a request of t as finished, closing t once it was dropped from the HTTP/3 pool
+// and no request uses it any more
+// releaseHTTP3 标记 t 的一个请求已结束，t 已被移出 HTTP/3 传输层池且不再有请求使用时将其关闭
+func (c *TransportSetting) releaseHTTP3(t *http3Transport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t.inFlight--
+	t.lastUsed = time.Now()
+	switch {
+	case t.dropped && t.inFlight == 0:
+		go t.close()
+	case len(c.http3Pool) > c.maxProxyTransports:
+		c.evictOldestHTTP3Locked()
+	}
+}
+
+// dropHTTP3Locked removes t from the HTTP/3 pool, closing it now or, while it is in use, once it is released
+// dropHTTP3Locked 将 t 移出 HTTP/3 传输层池，立即关闭，使用中时则在释放后关闭
+func (c *TransportSetting) dropHTTP3Locked(key string, t *http3Transport) {
+	delete(c.http3Pool, key)
+	t.dropped = true
+	if t.inFlight == 0 {
+		go t.close()
+	}
+}
+
+// evictOldestHTTP3Locked removes the least recently used HTTP/3 transport with no request in flight
+// evictOldestHTTP3Locked 移除没有进行中的请求且最久未使用的 HTTP/3 传输层
+func (c *TransportSetting) evictOldestHTTP3Locked() {
+	oldestKey := ""
+	var oldest *http3Transport
+	for key, t := range c.http3Pool {
+		if t.inFlight == 0 && (oldest == nil || t.lastUsed.Before(oldest.lastUsed)) {
+			oldestKey, oldest = key, t
+		}
+	}
+	if oldest != nil {
+		c.dropHTTP3Locked(oldestKey, oldest)
+	}
+}
+
+// resetHTTP3Locked drops every HTTP/3 transport, each is closed once no request uses it
+// resetHTTP3Locked 丢弃所有 HTTP/3 传输层，每个传输层在不再有请求使用时关闭
+func (c *TransportSetting) resetHTTP3Locked() {
+	for key, t := range c.http3Pool {
+		c.dropHTTP3Locked(key, t)
+	}
+}
+
+// quicAddrs returns the UDP addresses addr is dialed at: its override, or the addresses its host resolves to
+// quicAddrs 返回拨号 addr 时使用的 UDP 地址：其覆盖地址，或其主机解析出的地址
+func quicAddrs(ctx context.Context, addr string, requestOverrides, overrides map[string]string, r resolver.Resolver) ([]*net.UDPAddr, error) {
+	if target, ok := resolver.Override(requestOverrides, addr); ok {
+		addr = target
+	} else if target, ok := resolver.Override(overrides, addr); ok {
+		addr = target
+	}
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return []*net.UDPAddr{{IP: ip, Port: port}}, nil
+	}
+
+	var hosts []string
+	if r != nil {
+		hosts, err = r.LookupHost(ctx, host)
+	} else {
+		hosts, err = net.DefaultResolver.LookupHost(ctx, host)
+	}
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]*net.UDPAddr, 0, len(hosts))
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			addrs = append(addrs, &net.UDPAddr{IP: ip, Port: port})
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+// parseAltSvc returns the first h3 alternative of Alt-Svc header values for origin,
+// clear reports the "clear" value withdrawing all alternatives
+// parseAltSvc 返回 Alt-Svc 响应头中 origin 的第一个 h3 替代服务，clear 表示撤销所有替代服务的 "clear" 值
+func parseAltSvc(values []string, origin string) (alt altService, ok, clear bool) {
+	originHost, _, _ := net.SplitHostPort(origin)
+	for _, value := range values {
+		for _, entry := range strings.Split(value, ",") {
+			params := strings.Split(entry, ";")
+			first := strings.TrimSpace(params[0])
+			if first == "clear" {
+				return altService{}, false, true
+			}
+			protocol, authority, found := strings.Cut(first, "=")
+			if !found || strings.TrimSpace(protocol) != "h3" {
+				continue
+			}
+			host, port, err := net.SplitHostPort(strings.Trim(strings.TrimSpace(authority), `"`))
+			if err != nil || port == "" {
+				continue
+			}
+			if host == "" {
+				host = originHost
+			}
+			maxAge := defaultAltSvcMaxAge
+			for _, param := range params[1:] {
+				if name, v, found := strings.Cut(strings.TrimSpace(param), "="); found && name == "ma" {
+					if seconds, err := strconv.Atoi(strings.Trim(v, `"`)); err == nil {
+						maxAge = time.Duration(seconds) * time.Second
+					}
+				}
+			}
+			return altService{authority: net.JoinHostPort(host, port), expires: time.Now().Add(maxAge)}, true, false
+		}
+	}
+	return altService{}, false, false
+}
+
+// canonicalAddr returns host with the https default port added when it has none
+// canonicalAddr 返回 host，没有端口时补充 https 默认端口
+func canonicalAddr(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), "443")
+}
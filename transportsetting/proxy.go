@@ -0,0 +1,57 @@
+package transportsetting
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ProxySelector chooses the proxy of each request, e.g. a proxypool.Pool. It is accepted wherever a proxy is.
+// ProxySelector 为每个请求选择代理，例如 proxypool.Pool。所有接受代理配置的地方都接受它。
+type ProxySelector interface {
+	// Proxy returns the proxy of req, nil for a direct connection
+	// Proxy 返回 req 的代理，nil 表示直连
+	Proxy(req *http.Request) (*url.URL, error)
+}
+
+// ProxyReporter is implemented by proxy selectors that learn the outcome of the requests sent through their proxies
+// ProxyReporter 由需要了解经其代理发送的请求结果的代理选择器实现
+type ProxyReporter interface {
+	// ReportProxy records the outcome of a request sent through proxyURL. err is only set when no response arrived,
+	// statusCode is 0 then, and is the timeout error when the request timed out
+	// ReportProxy 记录经 proxyURL 发送的请求的结果。只有在没有收到响应时 err 才不为 nil，此时 statusCode 为 0，请求超时时 err 为超时错误
+	ReportProxy(proxyURL *url.URL, statusCode int, err error)
+}
+
+// proxyChoice is a proxy function together with the reporter of the selector it came from
+// proxyChoice 是代理函数及其来源选择器的报告者
+type proxyChoice struct {
+	proxy    func(*http.Request) (*url.URL, error) // Proxy function / 代理函数
+	reporter ProxyReporter                         // Reporter, nil when the proxy is not a ProxyReporter / 报告者，代理不是 ProxyReporter 时为 nil
+}
+
+// proxyRecorderContextKey is the context key for the callback recording the proxy a request was sent through
+// proxyRecorderContextKey 是记录请求所经代理的回调的上下文键
+type proxyRecorderContextKey struct{}
+
+// WithProxyRecorder returns a copy of ctx whose requests call record with the proxy they are sent through,
+// nil for direct connections; redirects call it again
+// WithProxyRecorder 返回一个 ctx 副本，其请求会以所经代理（直连时为 nil）调用 record；重定向会再次调用
+func WithProxyRecorder(ctx context.Context, record func(proxyURL *url.URL)) context.Context {
+	return context.WithValue(ctx, proxyRecorderContextKey{}, record)
+}
+
+// recordProxy calls the proxy recorder of ctx, if any
+// recordProxy 调用 ctx 中的代理记录回调（如果有）
+func recordProxy(ctx context.Context, proxyURL *url.URL) {
+	if record, ok := ctx.Value(proxyRecorderContextKey{}).(func(*url.URL)); ok {
+		record(proxyURL)
+	}
+}
+
+// reporterOf returns proxies as a ProxyReporter, nil if it is not one
+// reporterOf 将 proxies 作为 ProxyReporter 返回，不是时返回 nil
+func reporterOf(proxies interface{}) ProxyReporter {
+	reporter, _ := proxies.(ProxyReporter)
+	return reporter
+}
@@ -3,24 +3,160 @@
 package transportsetting
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/GoEnthusiast/httpreq/reqerr"
+	"github.com/GoEnthusiast/httpreq/resolver"
 	"golang.org/x/net/http2"
 )
 
-// TransportSetting manages HTTP transport configuration with thread-safe operations
-// TransportSetting 管理 HTTP 传输层配置，提供线程安全操作
+const (
+	defaultMaxProxyTransports        = 100             // Default maximum number of pooled per-proxy transports / 默认最大代理传输层池大小
+	defaultProxyTransportIdleTimeout = 5 * time.Minute // Default idle time before a per-proxy transport is evicted / 默认代理传输层空闲淘汰时间
+)
+
+// proxyContextKey is the context key for per-request proxy functions
+// proxyContextKey 是单请求代理函数的上下文键
+type proxyContextKey struct{}
+
+// connectTimeoutContextKey is the context key for per-request connect timeouts
+// connectTimeoutContextKey 是单请求连接超时的上下文键
+type connectTimeoutContextKey struct{}
+
+// resolveContextKey is the context key for per-request host:port to IP overrides
+// resolveContextKey 是单请求 host:port 到 IP 覆盖的上下文键
+type resolveContextKey struct{}
+
+// TransportSetting manages HTTP transport configuration with thread-safe operations.
+// It implements http.RoundTripper and sends every request through a transport dedicated to
+// the proxy the request resolves to, so requests with different proxies never share connections.
+// TransportSetting 管理 HTTP 传输层配置，提供线程安全操作。
+// 它实现了 http.RoundTripper，每个请求都会通过其解析出的代理所专属的传输层发送，不同代理的请求不会共享连接。
 type TransportSetting struct {
-	transport *http.Transport // HTTP transport instance / HTTP 传输层实例
-	mu        sync.Mutex      // Mutex for thread safety / 用于线程安全的互斥锁
+	transport                 *http.Transport             // HTTP transport instance used as template / 作为模板的 HTTP 传输层实例
+	dialer                    *net.Dialer                 // Default dialer, nil once a custom transport is set / 默认拨号器，设置自定义传输层后为 nil
+	connectTimeout            time.Duration               // Default TCP connect timeout / 默认 TCP 连接超时时间
+	enableHttp2               bool                        // Whether HTTP/2 is enabled / 是否启用 HTTP/2
+	resolver                  resolver.Resolver           // Custom resolver, nil uses the system resolver / 自定义解析器，nil 表示使用系统解析器
+	resolveOverrides          map[string]string           // host:port to IP overrides / host:port 到 IP 的覆盖
+	unixSockets               map[string]string           // Unix socket paths keyed by host:port or host / 按 host:port 或 host 索引的 Unix 套接字路径
+	proxyReporter             ProxyReporter               // Reporter of the requester-level proxy selector / 请求器级别代理选择器的报告者
+	dialContext               DialContextFunc             // Custom dial hook, nil uses the default dialer / 自定义拨号钩子，nil 表示使用默认拨号器
+	http3Mode                 HTTP3Mode                   // When requests are sent over HTTP/3 / 何时通过 HTTP/3 发送请求
+	http3Pool                 map[string]*http3Transport  // HTTP/3 transports keyed by per-request overrides / 按单请求覆盖索引的 HTTP/3 传输层
+	altServices               map[string]altService       // h3 alternative services keyed by origin host:port / 按源 host:port 索引的 h3 替代服务
+	http3Broken               map[string]time.Time        // Origins HTTP/3 failed on, until when it is not tried / HTTP/3 失败的源及不再尝试的截止时间
+	pool                      map[string]*pooledTransport // Transports keyed by proxy URL ("" means direct) and per-request overrides / 按代理地址（"" 表示直连）和单请求覆盖索引的传输层
+	maxProxyTransports        int                         // Maximum number of pooled transports / 传输层池最大数量
+	proxyTransportIdleTimeout time.Duration               // Idle time before a pooled transport is evicted / 池中传输层空闲淘汰时间
+	mu                        sync.Mutex                  // Mutex for thread safety / 用于线程安全的互斥锁
+}
+
+// pooledTransport is a transport bound to a single proxy
+// pooledTransport 是绑定到单个代理的传输层
+type pooledTransport struct {
+	transport *http.Transport // Transport with a fixed proxy / 固定代理的传输层
+	lastUsed  time.Time       // Last time the transport was used / 最后使用时间
+	inFlight  int             // Requests in flight, response bodies not yet closed included / 进行中的请求数（包括尚未关闭的响应体）
+	dropped   bool            // Whether it was removed from the pool while in use / 是否在使用中被移出传输层池
+}
+
+// trackedBody is a response body that releases its pooled transport when closed
+// trackedBody 是关闭时释放其池化传输层的响应体
+type trackedBody struct {
+	io.ReadCloser
+	once    sync.Once // Releases only once / 只释放一次
+	release func()    // Releases the pooled transport / 释放池化传输层
+}
+
+// Close closes the body and releases the pooled transport
+// Close 关闭响应体并释放池化传输层
+func (b *trackedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// ParseProxy converts a proxy configuration (string, function or ProxySelector) into a proxy function.
+// An empty string yields a nil function, which means a direct connection.
+// ParseProxy 将代理配置（字符串、函数或 ProxySelector）转换为代理函数。空字符串返回 nil 函数，表示直连。
+func ParseProxy(proxies interface{}) (func(*http.Request) (*url.URL, error), error) {
+	switch p := proxies.(type) {
+	case string:
+		if p == "" {
+			return nil, nil
+		}
+		proxyUrl, err := url.Parse(p)
+		if err != nil {
+			return nil, err
+		}
+		return http.ProxyURL(proxyUrl), nil
+
+	case func(r *http.Request) (*url.URL, error):
+		return p, nil
+
+	case ProxySelector:
+		return p.Proxy, nil
+
+	default:
+		return nil, fmt.Errorf("invalid proxy type: %T", proxies)
+	}
+}
+
+// WithProxy returns a copy of ctx carrying a per-request proxy that overrides the requester-level proxy.
+// A nil proxies value leaves ctx unchanged.
+// WithProxy 返回携带单请求代理的 ctx 副本，该代理会覆盖请求器级别的代理。proxies 为 nil 时 ctx 保持不变。
+func WithProxy(ctx context.Context, proxies interface{}) (context.Context, error) {
+	if proxies == nil {
+		return ctx, nil
+	}
+	proxy, err := ParseProxy(proxies)
+	if err != nil {
+		return ctx, err
+	}
+	if proxy == nil {
+		proxy = directProxy
+	}
+	return context.WithValue(ctx, proxyContextKey{}, proxyChoice{proxy: proxy, reporter: reporterOf(proxies)}), nil
+}
+
+// WithConnectTimeout returns a copy of ctx carrying a per-request TCP connect timeout.
+// A non-positive timeout leaves ctx unchanged.
+// WithConnectTimeout 返回携带单请求 TCP 连接超时的 ctx 副本。timeout 非正数时 ctx 保持不变。
+func WithConnectTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, connectTimeoutContextKey{}, timeout)
+}
+
+// WithResolve returns a copy of ctx carrying per-request host:port to IP overrides (see resolver.Override)
+// that take precedence over the requester-level ones. Empty overrides leave ctx unchanged.
+// WithResolve 返回携带单请求 host:port 到 IP 覆盖（见 resolver.Override）的 ctx 副本，其优先于请求器级别的覆盖。
+// overrides 为空时 ctx 保持不变。
+func WithResolve(ctx context.Context, overrides map[string]string) context.Context {
+	if len(overrides) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, resolveContextKey{}, overrides)
+}
+
+// directProxy is the proxy function for direct connections
+// directProxy 是直连使用的代理函数
+func directProxy(*http.Request) (*url.URL, error) {
+	return nil, nil
 }
 
 // SetTLS configures TLS settings with certificate files
@@ -59,6 +195,7 @@ func (c *TransportSetting) SetTLS(certPath, keyPath, caPath string) error {
 		tlsConfig.RootCAs = caCertPool
 	}
 	c.transport.TLSClientConfig = tlsConfig
+	c.resetPoolLocked()
 	return nil
 }
 
@@ -69,37 +206,27 @@ func (c *TransportSetting) SetTransport(transport *http.Transport) {
 	defer c.mu.Unlock()
 
 	c.transport = transport
+	c.dialer = nil
+	c.proxyReporter = nil
+	c.resetPoolLocked()
 }
 
 // SetProxy configures proxy settings
 // SetProxy 配置代理设置
 func (c *TransportSetting) SetProxy(proxies interface{}) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	if proxies == nil {
 		return nil
 	}
-
-	switch p := proxies.(type) {
-	case string:
-		if p == "" {
-			c.transport.Proxy = nil
-			return nil
-		}
-		proxyUrl, err := url.Parse(p)
-		if err != nil {
-			return err
-		}
-		c.transport.Proxy = http.ProxyURL(proxyUrl)
-
-	case func(r *http.Request) (*url.URL, error):
-		c.transport.Proxy = p
-
-	default:
-		return fmt.Errorf("invalid proxy type: %T", proxies)
+	proxy, err := ParseProxy(proxies)
+	if err != nil {
+		return err
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.transport.Proxy = proxy
+	c.proxyReporter = reporterOf(proxies)
 	return nil
 }
 
@@ -110,6 +237,7 @@ func (c *TransportSetting) SetMaxIdleConns(maxIdleConns int) {
 	defer c.mu.Unlock()
 
 	c.transport.MaxIdleConns = maxIdleConns
+	c.resetPoolLocked()
 }
 
 // SetMaxIdleConnsPerHost sets the maximum number of idle connections per host
@@ -119,6 +247,7 @@ func (c *TransportSetting) SetMaxIdleConnsPerHost(maxIdleConnsPerHost int) {
 	defer c.mu.Unlock()
 
 	c.transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	c.resetPoolLocked()
 }
 
 // SetMaxConnsPerHost sets the maximum number of connections per host
@@ -128,6 +257,7 @@ func (c *TransportSetting) SetMaxConnsPerHost(maxConnsPerHost int) {
 	defer c.mu.Unlock()
 
 	c.transport.MaxConnsPerHost = maxConnsPerHost
+	c.resetPoolLocked()
 }
 
 // SetIdleConnTimeout sets the idle connection timeout
@@ -137,6 +267,7 @@ func (c *TransportSetting) SetIdleConnTimeout(idleConnTimeout time.Duration) {
 	defer c.mu.Unlock()
 
 	c.transport.IdleConnTimeout = idleConnTimeout
+	c.resetPoolLocked()
 }
 
 // SetTLSHandshakeTimeout sets the TLS handshake timeout
@@ -146,6 +277,7 @@ func (c *TransportSetting) SetTLSHandshakeTimeout(tlsHandshakeTimeout time.Durat
 	defer c.mu.Unlock()
 
 	c.transport.TLSHandshakeTimeout = tlsHandshakeTimeout
+	c.resetPoolLocked()
 }
 
 // SetExpectContinueTimeout sets the Expect: 100-continue timeout
@@ -155,6 +287,7 @@ func (c *TransportSetting) SetExpectContinueTimeout(expectContinueTimeout time.D
 	defer c.mu.Unlock()
 
 	c.transport.ExpectContinueTimeout = expectContinueTimeout
+	c.resetPoolLocked()
 }
 
 // SetDisableKeepAlives sets whether to disable HTTP Keep-Alive
@@ -164,10 +297,356 @@ func (c *TransportSetting) SetDisableKeepAlives(disableKeepAlives bool) {
 	defer c.mu.Unlock()
 
 	c.transport.DisableKeepAlives = disableKeepAlives
+	c.resetPoolLocked()
+}
+
+// SetConnectTimeout sets the default TCP connect timeout used when a request does not set its own
+// SetConnectTimeout 设置请求未单独指定时使用的默认 TCP 连接超时时间
+func (c *TransportSetting) SetConnectTimeout(connectTimeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.connectTimeout = connectTimeout
+	c.resetPoolLocked()
+}
+
+// SetResolver sets the resolver host names are resolved with when dialing, e.g. a resolver.Cache or a
+// resolver.NewServerResolver; nil uses the system resolver
+// SetResolver 设置拨号时解析主机名使用的解析器，例如 resolver.Cache 或 resolver.NewServerResolver；nil 表示使用系统解析器
+func (c *TransportSetting) SetResolver(r resolver.Resolver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.resolver = r
+	c.resetPoolLocked()
+}
+
+// SetResolveOverrides sets the host:port to IP overrides used by every request instead of resolving, like curl --resolve.
+// Keys are "host:port" or "host" for any port, values an IP or "ip:port"; nil removes them.
+// SetResolveOverrides 设置所有请求使用的 host:port 到 IP 的覆盖，代替域名解析，类似 curl --resolve。
+// 键为 "host:port" 或表示任意端口的 "host"，值为 IP 或 "ip:port"；nil 表示移除。
+func (c *TransportSetting) SetResolveOverrides(overrides map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.resolveOverrides = make(map[string]string, len(overrides))
+	for key, value := range overrides {
+		c.resolveOverrides[key] = value
+	}
+	c.resetPoolLocked()
+}
+
+// SetMaxProxyTransports sets the maximum number of per-proxy transports kept in the pool. It is a soft cap: when every
+// pooled transport has a request in flight a new one is still created, and the pool shrinks back as requests finish.
+// SetMaxProxyTransports 设置池中保留的按代理划分的传输层最大数量。这是一个软上限：所有池化传输层都有进行中的请求时
+// 仍会创建新的传输层，请求结束后传输层池会缩减回上限。
+func (c *TransportSetting) SetMaxProxyTransports(maxProxyTransports int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if maxProxyTransports <= 0 {
+		maxProxyTransports = defaultMaxProxyTransports
+	}
+	c.maxProxyTransports = maxProxyTransports
+}
+
+// SetProxyTransportIdleTimeout sets how long an unused per-proxy transport is kept before eviction
+// SetProxyTransportIdleTimeout 设置未使用的按代理划分的传输层在被淘汰前保留的时间
+func (c *TransportSetting) SetProxyTransportIdleTimeout(proxyTransportIdleTimeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if proxyTransportIdleTimeout <= 0 {
+		proxyTransportIdleTimeout = defaultProxyTransportIdleTimeout
+	}
+	c.proxyTransportIdleTimeout = proxyTransportIdleTimeout
+}
+
+// RoundTrip resolves the proxy for req and sends it through the transport dedicated to that proxy, or over HTTP/3
+// for direct requests when the HTTP/3 mode asks for it, reporting the outcome to the proxy selector when it is a ProxyReporter
+// RoundTrip 解析 req 的代理，并通过该代理专属的传输层发送请求，HTTP/3 模式要求时直连请求通过 HTTP/3 发送，
+// 代理选择器为 ProxyReporter 时向其报告结果
+func (c *TransportSetting) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxyURL, reporter, err := c.resolveProxy(req)
+	if err != nil {
+		return nil, reqerr.New(reqerr.KindProxy, "resolve proxy error", err)
+	}
+	recordProxy(req.Context(), proxyURL)
+	overrides, _ := req.Context().Value(resolveContextKey{}).(map[string]string)
+	socketPath, _ := req.Context().Value(unixSocketContextKey{}).(string)
+	if proxyURL == nil && socketPath == "" {
+		resp, fallback, err := c.tryHTTP3(req, overrides)
+		if fallback == nil {
+			return resp, err
+		}
+		req = fallback
+	}
+	pt := c.transportFor(proxyURL, overrides, socketPath)
+	resp, err := pt.transport.RoundTrip(req)
+	release := func() { c.releaseTransport(pt) }
+	if err != nil || resp.StatusCode == http.StatusSwitchingProtocols {
+		// The connection of a protocol switch is taken over by the caller and no longer belongs to the transport
+		// 协议切换的连接由调用方接管，不再属于传输层
+		release()
+	} else {
+		resp.Body = &trackedBody{ReadCloser: resp.Body, release: release}
+	}
+	if err == nil && proxyURL == nil && socketPath == "" {
+		c.learnAltSvc(req, resp)
+	}
+	if reporter != nil && proxyURL != nil {
+		statusCode := 0
+		if resp != nil {
+			statusCode = resp.StatusCode
+		}
+		reporter.ReportProxy(proxyURL, statusCode, err)
+	}
+	return resp, err
+}
+
+// CloseIdleConnections closes idle connections of every pooled transport
+// CloseIdleConnections 关闭池中所有传输层的空闲连接
+func (c *TransportSetting) CloseIdleConnections() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, pt := range c.pool {
+		pt.transport.CloseIdleConnections()
+	}
+	for _, t := range c.http3Pool {
+		t.transport.CloseIdleConnections()
+	}
+	c.transport.CloseIdleConnections()
 }
 
-// GetTransport returns the configured HTTP transport
-// GetTransport 返回配置的 HTTP 传输层
+// resolveProxy returns the proxy URL for req and the reporter of its selector,
+// preferring the per-request proxy carried by its context
+// resolveProxy 返回 req 的代理地址及其选择器的报告者，优先使用其上下文中携带的单请求代理
+func (c *TransportSetting) resolveProxy(req *http.Request) (*url.URL, ProxyReporter, error) {
+	choice, ok := req.Context().Value(proxyContextKey{}).(proxyChoice)
+	if !ok {
+		c.mu.Lock()
+		choice = proxyChoice{proxy: c.transport.Proxy, reporter: c.proxyReporter}
+		c.mu.Unlock()
+	}
+	if choice.proxy == nil {
+		return nil, nil, nil
+	}
+	proxyURL, err := choice.proxy(req)
+	return proxyURL, choice.reporter, err
+}
+
+// transportFor acquires the pooled transport for proxyURL, the per-request overrides and Unix socket, creating it if necessary;
+// it must be released with releaseTransport. Requests with different overrides or sockets use different transports,
+// so they never share connections.
+// transportFor 获取 proxyURL、单请求覆盖和 Unix 套接字对应的池化传输层，必要时创建；使用后必须通过 releaseTransport 释放。
+// 覆盖或套接字不同的请求使用不同的传输层，因此不会共享连接。
+func (c *TransportSetting) transportFor(proxyURL *url.URL, overrides map[string]string, socketPath string) *pooledTransport {
+	key := ""
+	if proxyURL != nil {
+		key = proxyURL.String()
+	}
+	if len(overrides) > 0 {
+		key += " " + overridesKey(overrides)
+	}
+	if socketPath != "" {
+		key += " unix:" + socketPath
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.evictIdleLocked(now)
+	if pt, ok := c.pool[key]; ok {
+		pt.lastUsed = now
+		pt.inFlight++
+		return pt
+	}
+	if len(c.pool) >= c.maxProxyTransports {
+		c.evictOldestLocked()
+	}
+
+	transport := c.transport.Clone()
+	transport.DialContext = c.wrapDialContextLocked(transport.DialContext, overrides, socketPath)
+	transport.Proxy = nil
+	if proxyURL != nil {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	// The cloned HTTP/2 upgrade hook would share one connection pool across proxies, so configure a fresh one
+	// 克隆的 HTTP/2 升级钩子会在不同代理间共享连接池，因此重新配置一个
+	if c.enableHttp2 || transport.TLSNextProto["h2"] != nil {
+		transport.TLSNextProto = nil
+		_ = http2.ConfigureTransport(transport)
+	}
+	pt := &pooledTransport{transport: transport, lastUsed: now, inFlight: 1}
+	c.pool[key] = pt
+	return pt
+}
+
+// releaseTransport marks a request of pt as finished, closing the idle connections of pt once it was dropped from the pool
+// and no request uses it any more
+// releaseTransport 标记 pt 的一个请求已结束，pt 已被移出传输层池且不再有请求使用时关闭其空闲连接
+func (c *TransportSetting) releaseTransport(pt *pooledTransport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pt.inFlight--
+	pt.lastUsed = time.Now()
+	switch {
+	case pt.dropped && pt.inFlight == 0:
+		pt.transport.CloseIdleConnections()
+	case len(c.pool) > c.maxProxyTransports:
+		// The pool grew past the cap while every transport was busy, shrink it back
+		// 所有传输层都在使用时传输层池超过了上限，将其缩减回上限
+		c.evictOldestLocked()
+	}
+}
+
+// dropLocked removes pt from the pool, closing its idle connections now or, while it is in use, once it is released
+// dropLocked 将 pt 移出传输层池，立即关闭其空闲连接，使用中时则在释放后关闭
+func (c *TransportSetting) dropLocked(key string, pt *pooledTransport) {
+	delete(c.pool, key)
+	pt.dropped = true
+	if pt.inFlight == 0 {
+		pt.transport.CloseIdleConnections()
+	}
+}
+
+// wrapDialContextLocked applies the per-request or default connect timeout around dial (or the custom dial hook),
+// dials Unix sockets and resolves host names with the overrides (per-request ones first) or the custom resolver
+// wrapDialContextLocked 在拨号（或自定义拨号钩子）外层应用单请求或默认的连接超时，
+// 拨号 Unix 套接字，并使用覆盖（单请求覆盖优先）或自定义解析器解析主机名
+func (c *TransportSetting) wrapDialContextLocked(dial func(ctx context.Context, network, addr string) (net.Conn, error), overrides map[string]string, socketPath string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer, defaultTimeout := c.dialer, c.connectTimeout
+	if c.dialContext != nil {
+		dial, dialer = c.dialContext, nil
+	}
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	timedDial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		timeout, ok := ctx.Value(connectTimeoutContextKey{}).(time.Duration)
+		if !ok {
+			timeout = defaultTimeout
+		}
+		if timeout <= 0 {
+			return dial(ctx, network, addr)
+		}
+		// The default dialer has its own timeout, so use a copy carrying the requested one
+		// 默认拨号器自带超时时间，因此使用携带目标超时时间的副本
+		if dialer != nil {
+			d := *dialer
+			d.Timeout = timeout
+			return d.DialContext(ctx, network, addr)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return dial(ctx, network, addr)
+	}
+	resolved := resolvingDial(timedDial, overrides, c.resolveOverrides, c.resolver)
+	return socketDial(timedDial, resolved, socketPath, c.unixSockets)
+}
+
+// resolvingDial dials the override of addr if there is one, otherwise the addresses r resolves its host to, in order.
+// Without overrides and resolver dial is returned unchanged.
+// resolvingDial 如果 addr 有覆盖则拨号覆盖地址，否则依次拨号 r 解析出的主机地址。没有覆盖和解析器时原样返回 dial。
+func resolvingDial(dial func(ctx context.Context, network, addr string) (net.Conn, error), requestOverrides, overrides map[string]string, r resolver.Resolver) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if len(requestOverrides) == 0 && len(overrides) == 0 && r == nil {
+		return dial
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if target, ok := resolver.Override(requestOverrides, addr); ok {
+			return dial(ctx, network, target)
+		}
+		if target, ok := resolver.Override(overrides, addr); ok {
+			return dial(ctx, network, target)
+		}
+		host, port, err := net.SplitHostPort(addr)
+		if r == nil || err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+
+		addrs, err := r.LookupHost(ctx, host)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, reqerr.New(reqerr.KindDNS, "resolve host error", err)
+		}
+		if len(addrs) == 0 {
+			return nil, reqerr.New(reqerr.KindDNS, "resolve host error", &net.DNSError{Err: "no such host", Name: host, IsNotFound: true})
+		}
+		var conn net.Conn
+		for _, ip := range addrs {
+			if conn, err = dial(ctx, network, net.JoinHostPort(ip, port)); err == nil || ctx.Err() != nil {
+				break
+			}
+		}
+		return conn, err
+	}
+}
+
+// overridesKey returns a canonical string form of overrides for the transport pool key
+// overridesKey 返回 overrides 的规范字符串形式，用作传输层池的键
+func overridesKey(overrides map[string]string) string {
+	pairs := make([]string, 0, len(overrides))
+	for key, value := range overrides {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// evictIdleLocked removes pooled transports with no request in flight and unused for longer than the idle timeout
+// evictIdleLocked 移除没有进行中的请求且超过空闲时间未被使用的池化传输层
+func (c *TransportSetting) evictIdleLocked(now time.Time) {
+	for key, pt := range c.pool {
+		if pt.inFlight == 0 && now.Sub(pt.lastUsed) > c.proxyTransportIdleTimeout {
+			c.dropLocked(key, pt)
+		}
+	}
+}
+
+// evictOldestLocked removes the least recently used pooled transport with no request in flight
+// evictOldestLocked 移除没有进行中的请求且最久未使用的池化传输层
+func (c *TransportSetting) evictOldestLocked() {
+	oldestKey := ""
+	var oldest *pooledTransport
+	for key, pt := range c.pool {
+		if pt.inFlight == 0 && (oldest == nil || pt.lastUsed.Before(oldest.lastUsed)) {
+			oldestKey, oldest = key, pt
+		}
+	}
+	if oldest != nil {
+		c.dropLocked(oldestKey, oldest)
+	}
+}
+
+// PooledTransports returns the number of per-proxy transports in the pool, for monitoring
+// PooledTransports 返回池中按代理划分的传输层数量，用于监控
+func (c *TransportSetting) PooledTransports() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.pool)
+}
+
+// resetPoolLocked drops every pooled transport so that new settings take effect
+// resetPoolLocked 清空所有池化传输层，使新的设置生效
+func (c *TransportSetting) resetPoolLocked() {
+	for key, pt := range c.pool {
+		c.dropLocked(key, pt)
+	}
+	c.resetHTTP3Locked()
+}
+
+// GetTransport returns the template transport the pooled per-proxy transports are cloned from. No request is sent
+// through it, so changing its fields or closing its idle connections does not affect traffic.
+// GetTransport 返回池化的代理传输层所克隆的模板传输层。没有请求会通过它发送，因此修改其字段或关闭其空闲连接不会影响请求。
+//
+// Deprecated: change the transport with SetTransport and the Set* setters, and use CloseIdleConnections to close idle connections.
+// 已弃用：请使用 SetTransport 和各个 Set* 方法修改传输层，使用 CloseIdleConnections 关闭空闲连接。
 func (c *TransportSetting) GetTransport() *http.Transport {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -178,12 +657,21 @@ func (c *TransportSetting) GetTransport() *http.Transport {
 // NewTransportSetting creates a new transport setting with optional HTTP/2 support
 // NewTransportSetting 创建一个新的传输层设置，支持可选的 HTTP/2
 func NewTransportSetting(enableHttp2 bool) *TransportSetting {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second, // Maximum wait time for TCP connection establishment / 建立 TCP 连接的最大等待时间
+		KeepAlive: 30 * time.Second, // TCP KeepAlive interval / TCP KeepAlive 的时间间隔
+	}
 	result := &TransportSetting{
+		dialer:                    dialer,
+		enableHttp2:               enableHttp2,
+		pool:                      make(map[string]*pooledTransport),
+		http3Pool:                 make(map[string]*http3Transport),
+		altServices:               make(map[string]altService),
+		http3Broken:               make(map[string]time.Time),
+		maxProxyTransports:        defaultMaxProxyTransports,
+		proxyTransportIdleTimeout: defaultProxyTransportIdleTimeout,
 		transport: &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout:   30 * time.Second, // Maximum wait time for TCP connection establishment / 建立 TCP 连接的最大等待时间
-				KeepAlive: 30 * time.Second, // TCP KeepAlive interval / TCP KeepAlive 的时间间隔
-			}).DialContext,
+			DialContext:           dialer.DialContext,
 			DisableKeepAlives:     false,            // Whether to disable HTTP Keep-Alive (false means enabled) / 是否禁用 HTTP Keep-Alive（false 表示开启 Keep-Alive）
 			MaxIdleConns:          1000,             // Global maximum idle connections / 全局最大空闲连接数
 			MaxIdleConnsPerHost:   1000,             // Maximum idle connections per host / 每个主机的最大空闲连接数
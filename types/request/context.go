@@ -0,0 +1,20 @@
+package request
+
+import "context"
+
+// contextKey is the context key for the request being sent
+// contextKey 是正在发送的请求的上下文键
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying req, so transport-level hooks such as proxy selectors can read it
+// NewContext 返回携带 req 的 ctx 副本，使代理选择器等传输层钩子可以读取它
+func NewContext(ctx context.Context, req *Request) context.Context {
+	return context.WithValue(ctx, contextKey{}, req)
+}
+
+// FromContext returns the request carried by ctx, nil if there is none
+// FromContext 返回 ctx 中携带的请求，没有时返回 nil
+func FromContext(ctx context.Context) *Request {
+	req, _ := ctx.Value(contextKey{}).(*Request)
+	return req
+}
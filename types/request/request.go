@@ -3,21 +3,42 @@
 package request
 
 import (
+	"io"
 	"net/http"
 	"time"
 
+	"github.com/GoEnthusiast/httpreq/auth"
+	"github.com/GoEnthusiast/httpreq/hedge"
 	"github.com/GoEnthusiast/httpreq/method"
+	"github.com/GoEnthusiast/httpreq/redirect"
+	"github.com/GoEnthusiast/httpreq/retry"
+	"github.com/GoEnthusiast/httpreq/signer"
 )
 
 // Request represents an HTTP request with all necessary parameters
 // Request 表示包含所有必要参数的 HTTP 请求
 type Request struct {
-	Method      method.HTTPMethod      // HTTP request method (GET, POST, PUT, DELETE) / HTTP 请求方法 (GET, POST, PUT, DELETE)
-	URL         string                 // Request URL / 请求地址
-	Header      http.Header            // HTTP request headers / HTTP 请求头
-	Body        interface{}            // Request body data / 请求体数据
-	ContentType method.HTTPContentType // Content-Type header value / 请求内容类型
-	Proxy       interface{}            // Proxy configuration (string or function) / 代理配置 (字符串或函数)
-	Timeout     time.Duration          // Request timeout duration / 请求超时时间
-	Meta        map[string]interface{} // Request metadata for custom use / 请求元数据，供自定义使用
+	Method            method.HTTPMethod      // HTTP request method (GET, POST, PUT, DELETE) / HTTP 请求方法 (GET, POST, PUT, DELETE)
+	URL               string                 // Request URL, may contain {name} path placeholders / 请求地址，可以包含 {name} 路径占位符
+	PathParams        map[string]string      // Values of the URL path placeholders, escaped when applied / URL 路径占位符的值，应用时会转义
+	Query             interface{}            // Query parameters (url.Values, map or struct with url tags) merged into the URL / 合并到地址中的查询参数 (url.Values、map 或带 url 标签的结构体)
+	Header            http.Header            // HTTP request headers / HTTP 请求头
+	Body              interface{}            // Request body data / 请求体数据
+	ContentType       method.HTTPContentType // Content-Type header value / 请求内容类型
+	Proxy             interface{}            // Proxy configuration (string, function or proxy pool) / 代理配置 (字符串、函数或代理池)
+	Resolve           map[string]string      // host:port to IP overrides like curl --resolve, taking precedence over the requester ones / 类似 curl --resolve 的 host:port 到 IP 覆盖，优先于请求器级别的覆盖
+	Timeout           time.Duration          // Total request timeout, the requester default is used when zero / 请求总超时时间，为零时使用请求器默认值
+	ConnectTimeout    time.Duration          // TCP connect timeout / TCP 连接超时时间
+	HeaderTimeout     time.Duration          // Response header timeout / 响应头超时时间
+	StreamBody        bool                   // Return the body as Response.BodyReader instead of reading it into memory / 以 Response.BodyReader 返回响应体，而不是读入内存
+	BodyWriter        io.Writer              // Write a successful (2xx) response body into this writer, not retried once bytes were written / 将成功 (2xx) 的响应体写入该写入器，已写入字节后不再重试
+	OutputFile        string                 // Write a successful (2xx) response body into this file, recreated by every retry / 将成功 (2xx) 的响应体写入该文件，每次重试都重新创建
+	MaxBodySize       int64                  // Maximum response body size in bytes, 0 uses the requester default, negative means unlimited / 最大响应体字节数，0 使用请求器默认值，负数表示不限制
+	KeepTruncatedBody bool                   // Keep the body prefix read before exceeding MaxBodySize / 保留超过 MaxBodySize 之前读取的响应体前缀
+	Retry             *retry.Policy          // Retry policy, the requester default is used when nil / 重试策略，为 nil 时使用请求器默认值
+	Redirect          *redirect.Policy       // Redirect policy, the requester default is used when nil / 重定向策略，为 nil 时使用请求器默认值
+	Hedge             *hedge.Policy          // Hedging policy for idempotent requests, the requester default is used when nil / 幂等请求的对冲策略，为 nil 时使用请求器默认值
+	Auth              auth.Provider          // Authentication provider, the requester default is used when nil / 认证提供者，为 nil 时使用请求器默认值
+	Signer            signer.Signer          // Request signer, the requester default is used when nil / 请求签名器，为 nil 时使用请求器默认值
+	Meta              map[string]interface{} // Request metadata for custom use / 请求元数据，供自定义使用
 }
@@ -0,0 +1,138 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RateLimit holds the rate limit information advertised by the server
+// RateLimit 保存服务器声明的限流信息
+type RateLimit struct {
+	Limit     int    // Maximum requests allowed in the window, -1 if absent / 时间窗口内允许的最大请求数，缺失时为 -1
+	Remaining int    // Requests remaining in the window, -1 if absent / 时间窗口内剩余请求数，缺失时为 -1
+	Reset     string // Raw reset value (seconds or timestamp, server specific) / 原始重置值（秒数或时间戳，取决于服务器）
+}
+
+// GetHeader returns the first value of the response header key
+// GetHeader 返回响应头 key 的第一个值
+func (r *Response) GetHeader(key string) string {
+	return r.Header.Get(key)
+}
+
+// Location returns the Location response header
+// Location 返回 Location 响应头
+func (r *Response) Location() string {
+	return r.Header.Get("Location")
+}
+
+// ETag returns the ETag response header
+// ETag 返回 ETag 响应头
+func (r *Response) ETag() string {
+	return r.Header.Get("ETag")
+}
+
+// Cookie returns the cookie set by the response with the given name, or nil if absent
+// Cookie 返回响应设置的指定名称的 Cookie，不存在时返回 nil
+func (r *Response) Cookie(name string) *http.Cookie {
+	for _, cookie := range r.Cookies {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// IsHTTP2 reports whether the response was received over HTTP/2
+// IsHTTP2 报告响应是否通过 HTTP/2 接收
+func (r *Response) IsHTTP2() bool {
+	return strings.HasPrefix(r.Proto, "HTTP/2")
+}
+
+// Links parses the Link response headers (RFC 8288) into a map of rel to URL
+// Links 将 Link 响应头 (RFC 8288) 解析为 rel 到 URL 的映射
+func (r *Response) Links() map[string]string {
+	links := make(map[string]string)
+	for _, header := range r.Header.Values("Link") {
+		for _, link := range splitLinks(header) {
+			start, end := strings.Index(link, "<"), strings.Index(link, ">")
+			if start < 0 || end < start {
+				continue
+			}
+			target := link[start+1 : end]
+			for _, param := range strings.Split(link[end+1:], ";") {
+				key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				// A rel value may hold several space separated relation types
+				// rel 的值可能包含多个以空格分隔的关系类型
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+					links[strings.ToLower(rel)] = target
+				}
+			}
+		}
+	}
+	return links
+}
+
+// Link returns the URL of the Link header with the given rel (e.g. "next"), or "" if absent
+// Link 返回指定 rel（例如 "next"）的 Link 头部地址，不存在时返回 ""
+func (r *Response) Link(rel string) string {
+	return r.Links()[strings.ToLower(rel)]
+}
+
+// RateLimit returns the rate limit headers (X-RateLimit-* or RateLimit-*) advertised by the server
+// RateLimit 返回服务器声明的限流头部（X-RateLimit-* 或 RateLimit-*）
+func (r *Response) RateLimit() RateLimit {
+	return RateLimit{
+		Limit:     headerInt(r.Header, "X-RateLimit-Limit", "RateLimit-Limit"),
+		Remaining: headerInt(r.Header, "X-RateLimit-Remaining", "RateLimit-Remaining"),
+		Reset:     headerValue(r.Header, "X-RateLimit-Reset", "RateLimit-Reset"),
+	}
+}
+
+// splitLinks splits a Link header value on commas that are not inside angle brackets
+// splitLinks 按不在尖括号内的逗号拆分 Link 头部值
+func splitLinks(header string) []string {
+	var (
+		links   []string
+		inURL   bool
+		lastCut int
+	)
+	for i, ch := range header {
+		switch ch {
+		case '<':
+			inURL = true
+		case '>':
+			inURL = false
+		case ',':
+			if !inURL {
+				links = append(links, header[lastCut:i])
+				lastCut = i + 1
+			}
+		}
+	}
+	return append(links, header[lastCut:])
+}
+
+// headerValue returns the first non-empty value among keys
+// headerValue 返回 keys 中第一个非空的值
+func headerValue(header http.Header, keys ...string) string {
+	for _, key := range keys {
+		if value := header.Get(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// headerInt returns the first integer value among keys, or -1 if none is present
+// headerInt 返回 keys 中第一个整数值，均不存在时返回 -1
+func headerInt(header http.Header, keys ...string) int {
+	value, err := strconv.Atoi(headerValue(header, keys...))
+	if err != nil {
+		return -1
+	}
+	return value
+}
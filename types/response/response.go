@@ -3,19 +3,73 @@
 package response
 
 import (
+	"crypto/tls"
+	"io"
+	"net/http"
 	"time"
 
+	"github.com/GoEnthusiast/httpreq/cache"
 	"github.com/GoEnthusiast/httpreq/types/request"
 )
 
 // Response represents an HTTP response with timing and error information
 // Response 表示包含时间和错误信息的 HTTP 响应
 type Response struct {
-	Request            *request.Request // Original request object / 原始请求对象
-	ResponseStatusCode int              // HTTP response status code / HTTP 响应状态码
-	ResponseBody       []byte           // Response body content / 响应体内容
-	Error              error            // Error occurred during request / 请求过程中发生的错误
-	StartTime          time.Time        // Request start time / 请求开始时间
-	EndTime            time.Time        // Request end time / 请求结束时间
-	Duration           float64          // Request duration in milliseconds / 请求耗时（毫秒）
+	Request            *request.Request     // Original request object / 原始请求对象
+	ResponseStatusCode int                  // HTTP response status code / HTTP 响应状态码
+	ResponseBody       []byte               // Response body content / 响应体内容
+	BodyReader         io.ReadCloser        // Streamed response body, set when Request.StreamBody is true and must be closed / 流式响应体，Request.StreamBody 为 true 时设置，必须关闭
+	BodySize           int64                // Number of response body bytes read / 已读取的响应体字节数
+	Header             http.Header          // Response headers / 响应头
+	Cookies            []*http.Cookie       // Cookies set by the response (Set-Cookie) / 响应设置的 Cookie (Set-Cookie)
+	Proto              string               // Negotiated protocol, e.g. "HTTP/1.1", "HTTP/2.0" or "HTTP/3.0" / 协商的协议，例如 "HTTP/1.1"、"HTTP/2.0" 或 "HTTP/3.0"
+	ContentLength      int64                // Content-Length reported by the server, -1 if unknown / 服务器报告的 Content-Length，未知时为 -1
+	Trailer            http.Header          // Response trailers, available after the body is read / 响应尾部字段，读取响应体后可用
+	TLS                *tls.ConnectionState // TLS connection state, nil for plain HTTP / TLS 连接状态，普通 HTTP 时为 nil
+	FinalURL           string               // Final URL after redirects / 重定向后的最终地址
+	Proxy              string               // Proxy the final attempt was sent through, password redacted / 最后一次尝试所经的代理（隐藏密码）
+	Endpoint           string               // Upstream endpoint chosen by the load balancer for the final attempt / 负载均衡器为最后一次尝试选择的上游端点
+	Redirects          []Redirect           // Redirects followed, oldest first / 已跟随的重定向（按时间顺序）
+	Error              error                // Error occurred during request / 请求过程中发生的错误
+	StartTime          time.Time            // Request start time / 请求开始时间
+	EndTime            time.Time            // Request end time / 请求结束时间
+	Duration           float64              // Request duration in seconds / 请求耗时（秒）
+	CacheStatus        cache.Status         // How the HTTP cache took part: none, hit, revalidated or miss / HTTP 缓存的参与方式：未使用、命中、重新验证或未命中
+	RateLimitWait      time.Duration        // Time spent waiting for rate limiters, all attempts included / 等待限流器的时间（包括所有尝试）
+	Timing             Timing               // Phase timing of the final attempt / 最后一次尝试的各阶段耗时
+	Attempts           []Attempt            // Every attempt made, including retries / 所有尝试记录（包括重试）
+	Hedges             int                  // Extra copies sent by hedging for the final attempt / 最后一次尝试中对冲发送的额外副本数
+	HedgeWinner        int                  // Copy that produced this response: 0 the original, n the n-th hedge / 产生该响应的副本：0 为原始请求，n 为第 n 个对冲副本
+}
+
+// Timing is the phase breakdown of an attempt captured with net/http/httptrace.
+// Phases that did not happen, such as DNS lookup and connect on a reused connection, are zero.
+// Timing 是通过 net/http/httptrace 采集的单次尝试各阶段耗时，未发生的阶段（例如复用连接时的 DNS 解析和连接）为 0。
+type Timing struct {
+	DNSLookup       time.Duration // DNS lookup time / DNS 解析耗时
+	TCPConnect      time.Duration // TCP connect time / TCP 连接耗时
+	TLSHandshake    time.Duration // TLS handshake time / TLS 握手耗时
+	TimeToFirstByte time.Duration // Time from the attempt start to the first response byte / 从尝试开始到收到第一个响应字节的耗时
+	BodyTransfer    time.Duration // Time from the first response byte until the body was read / 从第一个响应字节到响应体读取完成的耗时
+	ConnReused      bool          // Whether an idle connection was reused / 是否复用了空闲连接
+	RemoteAddr      string        // Remote address of the connection / 连接的远端地址
+}
+
+// Redirect records one followed redirect
+// Redirect 记录一次已跟随的重定向
+type Redirect struct {
+	URL        string      // URL that answered with the redirect / 返回重定向的地址
+	StatusCode int         // Redirect status code / 重定向状态码
+	Header     http.Header // Headers of the redirect response / 重定向响应的响应头
+	Location   string      // Resolved URL the redirect pointed to / 重定向指向的解析后地址
+}
+
+// Attempt records the outcome of a single attempt of a request
+// Attempt 记录请求单次尝试的结果
+type Attempt struct {
+	Number     int           // Attempt number starting at 1 / 尝试序号，从 1 开始
+	StatusCode int           // HTTP response status code, 0 if no response / HTTP 响应状态码，无响应时为 0
+	Error      error         // Error of the attempt / 本次尝试的错误
+	StartTime  time.Time     // Attempt start time / 尝试开始时间
+	Duration   time.Duration // Attempt duration / 尝试耗时
 }